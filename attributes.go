@@ -0,0 +1,224 @@
+package graph
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Attributes holds named values attached to a node or an edge, such that
+// several subsystems can attach data without clobbering each other.
+// Use an AttrKey for typed access.
+type Attributes struct {
+	values map[string]interface{}
+
+	// graph and node own node attributes, used for maintaining the indexes
+	graph *Graph
+	node  *Node
+}
+
+// Attrs returns the attributes of the node
+func (n *Node) Attrs() *Attributes {
+	if n.attrs == nil {
+		n.attrs = &Attributes{values: make(map[string]interface{}), graph: n.graph, node: n}
+	}
+
+	return n.attrs
+}
+
+// Attrs returns the attributes of the edge
+func (e *Edge) Attrs() *Attributes {
+	if e.attrs == nil {
+		e.attrs = &Attributes{values: make(map[string]interface{})}
+	}
+
+	return e.attrs
+}
+
+// Len returns the number of attributes
+func (a *Attributes) Len() int {
+	return len(a.values)
+}
+
+// Value returns the untyped value of the named attribute
+func (a *Attributes) Value(name string) (value interface{}, hasValue bool) {
+	value, hasValue = a.values[name]
+	return
+}
+
+// Keys returns the names of the attributes in sorted order
+func (a *Attributes) Keys() []string {
+	keys := make([]string, 0, len(a.values))
+	for name := range a.values {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Range calls f for every attribute in the order of their names, until f returns false
+func (a *Attributes) Range(f func(name string, value interface{}) bool) {
+	for _, name := range a.Keys() {
+		if !f(name, a.values[name]) {
+			return
+		}
+	}
+}
+
+func (a *Attributes) set(name string, value interface{}) {
+	if a.graph != nil {
+		if old, hasOld := a.values[name]; hasOld {
+			a.graph.unindexAttr(a.node, name, old)
+		}
+		a.graph.indexAttr(a.node, name, value)
+	}
+
+	a.values[name] = value
+}
+
+func (a *Attributes) delete(name string) {
+	old, hasOld := a.values[name]
+	if !hasOld {
+		return
+	}
+
+	if a.graph != nil {
+		a.graph.unindexAttr(a.node, name, old)
+	}
+	delete(a.values, name)
+}
+
+// AttrKey gives typed access to the attribute with its name
+type AttrKey[T any] struct {
+	name string
+}
+
+// NewAttrKey returns the key of the named attribute of type T
+func NewAttrKey[T any](name string) AttrKey[T] {
+	return AttrKey[T]{name: name}
+}
+
+// Name returns the name of the attribute
+func (k AttrKey[T]) Name() string {
+	return k.name
+}
+
+// Get returns the value of the attribute.
+// If the attribute is missing or of another type, the zero value and false are returned.
+func (k AttrKey[T]) Get(a *Attributes) (value T, hasValue bool) {
+	value, hasValue = a.values[k.name].(T)
+	return
+}
+
+// Set sets the value of the attribute
+func (k AttrKey[T]) Set(a *Attributes, value T) {
+	a.set(k.name, value)
+}
+
+// Delete removes the attribute
+func (k AttrKey[T]) Delete(a *Attributes) {
+	a.delete(k.name)
+}
+
+// Find returns the nodes of the graph having the attribute with the given value, ordered by id
+func (k AttrKey[T]) Find(g *Graph, value T) []*Node {
+	return g.FindByAttr(k.name, value)
+}
+
+// IndexNodeAttr maintains an index of the nodes by the value of the named attribute,
+// such that FindByAttr does not scan all nodes. Values that are not comparable are left out of the index.
+func (g *Graph) IndexNodeAttr(name string) {
+	if g.attrIndexes == nil {
+		g.attrIndexes = make(map[string]map[interface{}][]*Node)
+	}
+	if _, indexed := g.attrIndexes[name]; indexed {
+		return
+	}
+
+	g.attrIndexes[name] = make(map[interface{}][]*Node)
+	for _, n := range g.sortedNodes() {
+		if n.attrs == nil {
+			continue
+		}
+
+		if value, hasValue := n.attrs.values[name]; hasValue {
+			g.indexAttr(n, name, value)
+		}
+	}
+}
+
+// FindByAttr returns the nodes having the named attribute with the given value, ordered by id.
+// Values that are not comparable, such as slices and maps, are compared with reflect.DeepEqual,
+// in which case all nodes are scanned even if the attribute is indexed.
+func (g *Graph) FindByAttr(name string, value interface{}) []*Node {
+	if index, indexed := g.attrIndexes[name]; indexed && isComparable(value) {
+		nodes := make([]*Node, len(index[value]))
+		copy(nodes, index[value])
+		return nodes
+	}
+
+	var nodes []*Node
+	for _, n := range g.sortedNodes() {
+		if n.attrs == nil {
+			continue
+		}
+
+		if v, hasValue := n.attrs.values[name]; hasValue && attrEqual(v, value) {
+			nodes = append(nodes, n)
+		}
+	}
+
+	return nodes
+}
+
+// attrEqual compares attribute values, falling back to reflect.DeepEqual for values
+// that would panic with ==
+func attrEqual(a, b interface{}) bool {
+	if isComparable(a) && isComparable(b) {
+		return a == b
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// isComparable reports whether the value can be compared with == and used as a map key
+func isComparable(value interface{}) bool {
+	return value == nil || reflect.TypeOf(value).Comparable()
+}
+
+func (g *Graph) indexAttr(n *Node, name string, value interface{}) {
+	index, indexed := g.attrIndexes[name]
+	if !indexed || !isComparable(value) {
+		return
+	}
+
+	nodes := index[value]
+	i := sort.Search(len(nodes), func(i int) bool {
+		return nodes[i].ID >= n.ID
+	})
+	nodes = append(nodes, nil)
+	copy(nodes[i+1:], nodes[i:])
+	nodes[i] = n
+	index[value] = nodes
+}
+
+func (g *Graph) unindexAttr(n *Node, name string, value interface{}) {
+	index, indexed := g.attrIndexes[name]
+	if !indexed || !isComparable(value) {
+		return
+	}
+
+	nodes := index[value]
+	for i, m := range nodes {
+		if m == n {
+			nodes = append(nodes[:i], nodes[i+1:]...)
+			break
+		}
+	}
+
+	if len(nodes) == 0 {
+		delete(index, value)
+	} else {
+		index[value] = nodes
+	}
+}
@@ -0,0 +1,119 @@
+package graph
+
+import "testing"
+
+func TestAttributes(t *testing.T) {
+	g := NewGraph()
+	n := g.NewNode(1)
+	e := n.DependOn(g.NewNode(2))
+
+	owner := NewAttrKey[string]("owner")
+	size := NewAttrKey[int]("size")
+
+	owner.Set(n.Attrs(), "parser")
+	size.Set(n.Attrs(), 42)
+	size.Set(e.Attrs(), 7)
+
+	if v, hasValue := owner.Get(n.Attrs()); !hasValue || v != "parser" {
+		t.Errorf("Expected parser, got %v", v)
+	}
+
+	if v, hasValue := size.Get(e.Attrs()); !hasValue || v != 7 {
+		t.Errorf("Expected 7, got %v", v)
+	}
+
+	// The same name with another type does not match
+	if _, hasValue := NewAttrKey[string]("size").Get(n.Attrs()); hasValue {
+		t.Errorf("Expected no string value")
+	}
+
+	keys := n.Attrs().Keys()
+	if len(keys) != 2 || keys[0] != "owner" || keys[1] != "size" {
+		t.Errorf("Expected owner and size, got %v", keys)
+	}
+
+	var names []string
+	n.Attrs().Range(func(name string, value interface{}) bool {
+		names = append(names, name)
+		return false
+	})
+	if len(names) != 1 || names[0] != "owner" {
+		t.Errorf("Expected to stop after owner, got %v", names)
+	}
+
+	size.Delete(n.Attrs())
+	if _, hasValue := size.Get(n.Attrs()); hasValue || n.Attrs().Len() != 1 {
+		t.Errorf("Expected the size to be deleted")
+	}
+}
+
+func TestGraph_findByAttr(t *testing.T) {
+	g := NewGraph()
+	owner := NewAttrKey[string]("owner")
+	for i := 0; i < 6; i++ {
+		n := g.NewNode(i)
+		if i%2 == 0 {
+			owner.Set(n.Attrs(), "even")
+		} else {
+			owner.Set(n.Attrs(), "odd")
+		}
+	}
+
+	scanned := owner.Find(g, "even")
+	g.IndexNodeAttr("owner")
+	indexed := owner.Find(g, "even")
+	if len(scanned) != 3 || len(indexed) != 3 {
+		t.Fatalf("Expected 3 even nodes, got %v and %v", scanned, indexed)
+	}
+	for i := range scanned {
+		if scanned[i] != indexed[i] {
+			t.Errorf("Expected %v, got %v", scanned, indexed)
+		}
+	}
+
+	owner.Set(g.Find(0).Attrs(), "odd")
+	owner.Delete(g.Find(2).Attrs())
+	owner.Set(g.NewNode(6).Attrs(), "even")
+
+	even := owner.Find(g, "even")
+	if len(even) != 2 || even[0] != g.Find(4) || even[1] != g.Find(6) {
+		t.Errorf("Expected 4 and 6, got %v", even)
+	}
+
+	odd := g.FindByAttr("owner", "odd")
+	if len(odd) != 4 || odd[0] != g.Find(0) {
+		t.Errorf("Expected 0, 1, 3 and 5, got %v", odd)
+	}
+}
+
+func TestGraph_findByAttrNonComparable(t *testing.T) {
+	g := NewGraph()
+	tags := NewAttrKey[[]string]("tags")
+	tags.Set(g.NewNode(1).Attrs(), []string{"a", "b"})
+	tags.Set(g.NewNode(2).Attrs(), []string{"c"})
+	g.NewNode(3).Attrs().set("tags", "a")
+
+	found := tags.Find(g, []string{"a", "b"})
+	if len(found) != 1 || found[0] != g.Find(1) {
+		t.Errorf("Expected node 1, got %v", found)
+	}
+
+	if found := g.FindByAttr("tags", "a"); len(found) != 1 || found[0] != g.Find(3) {
+		t.Errorf("Expected node 3, got %v", found)
+	}
+
+	// Indexing skips the slices, which are still found by scanning
+	g.IndexNodeAttr("tags")
+	tags.Set(g.NewNode(4).Attrs(), []string{"a", "b"})
+	tags.Set(g.Find(2).Attrs(), []string{"a", "b"})
+	tags.Delete(g.Find(1).Attrs())
+
+	found = tags.Find(g, []string{"a", "b"})
+	if len(found) != 2 || found[0] != g.Find(2) || found[1] != g.Find(4) {
+		t.Errorf("Expected nodes 2 and 4, got %v", found)
+	}
+
+	if found := g.FindByAttr("tags", "a"); len(found) != 1 || found[0] != g.Find(3) {
+		t.Errorf("Expected node 3 from the index, got %v", found)
+	}
+}
@@ -0,0 +1,417 @@
+package graph
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"sort"
+)
+
+// The binary snapshot format is laid out as follows, all integers being
+// unsigned varints unless noted otherwise:
+//
+//	magic      "GRPH"
+//	version
+//	flags      graph modes, see the binaryFlag constants
+//	nodes      count, then per node: id, data payload, region payload
+//	adjacency  per node in the same order: out degree, then per edge:
+//	           destination id, kind, label payload, edge data payload
+//	regions    count, then per nested region: region payload, parent payload
+//	checksum   CRC-32 (IEEE) of everything above, 4 bytes big endian
+//
+// Version 1 snapshots have no edge kinds, their edges are read as hard edges.
+// Snapshots before version 3 have no edge labels, and before version 4 no region parents.
+//
+// A payload is encoded as its length plus one followed by the bytes
+// produced by the PayloadCodec. A length of zero denotes a nil payload.
+const (
+	binaryMagic   = "GRPH"
+	binaryVersion = 4
+
+	maxPayloadSize = 1 << 30
+)
+
+const (
+	binaryFlagMultigraph uint64 = 1 << iota
+	binaryFlagUndirected
+	binaryFlagSelfLoops
+)
+
+var (
+	// ErrInvalidFormat is returned when the input is not a graph snapshot.
+	ErrInvalidFormat = errors.New("graph: invalid binary format")
+
+	// ErrUnsupportedVersion is returned when the snapshot was written by a newer version.
+	ErrUnsupportedVersion = errors.New("graph: unsupported binary version")
+
+	// ErrChecksumMismatch is returned when the snapshot is corrupt.
+	ErrChecksumMismatch = errors.New("graph: checksum mismatch")
+)
+
+// PayloadCodec converts node data, regions and edge data to and from bytes.
+type PayloadCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(b []byte) (interface{}, error)
+}
+
+// GobCodec is a PayloadCodec using encoding/gob.
+// Types other than the predeclared ones must be registered with gob.Register.
+type GobCodec struct{}
+
+type gobPayload struct {
+	V interface{}
+}
+
+// Marshal encodes v using gob.
+func (GobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&gobPayload{v}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes a gob encoded value.
+func (GobCodec) Unmarshal(b []byte) (interface{}, error) {
+	var p gobPayload
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&p); err != nil {
+		return nil, err
+	}
+
+	return p.V, nil
+}
+
+// WriteBinary writes a snapshot of the graph to w.
+// If codec is nil, GobCodec is used.
+func (g *Graph) WriteBinary(w io.Writer, codec PayloadCodec) error {
+	if codec == nil {
+		codec = GobCodec{}
+	}
+
+	bw := bufio.NewWriter(w)
+	h := crc32.NewIEEE()
+	enc := &binaryEncoder{w: io.MultiWriter(bw, h), codec: codec}
+
+	enc.writeBytes([]byte(binaryMagic))
+	enc.writeUvarint(binaryVersion)
+	enc.writeUvarint(g.binaryFlags())
+
+	nodes := g.sortedNodes()
+	enc.writeUvarint(uint64(len(nodes)))
+	for _, n := range nodes {
+		enc.writeUvarint(uint64(n.ID))
+		enc.writePayload(n.Data)
+		enc.writePayload(n.Region)
+	}
+
+	for _, n := range nodes {
+		var out []*Edge
+		for _, e := range n.Edges {
+			if e.Source == n {
+				out = append(out, e)
+			}
+		}
+
+		enc.writeUvarint(uint64(len(out)))
+		for _, e := range out {
+			enc.writeUvarint(uint64(e.Destination.ID))
+			enc.writeUvarint(uint64(e.Kind))
+			enc.writePayload(e.Label)
+			enc.writePayload(e.Data)
+		}
+	}
+
+	// The regions are sorted to write the same snapshot for the same graph
+	regions := make([]interface{}, 0, len(g.RegionParents))
+	for region := range g.RegionParents {
+		regions = append(regions, region)
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		return fmt.Sprintf("%T %v", regions[i], regions[i]) < fmt.Sprintf("%T %v", regions[j], regions[j])
+	})
+
+	enc.writeUvarint(uint64(len(regions)))
+	for _, region := range regions {
+		enc.writePayload(region)
+		enc.writePayload(g.RegionParents[region])
+	}
+
+	if enc.err != nil {
+		return enc.err
+	}
+
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], h.Sum32())
+	if _, err := bw.Write(sum[:]); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// ReadBinary reads a graph snapshot written by WriteBinary.
+// If codec is nil, GobCodec is used.
+func ReadBinary(r io.Reader, codec PayloadCodec) (*Graph, error) {
+	g := NewGraph()
+	if err := g.ReadBinary(r, codec); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// ReadBinary reads a graph snapshot written by WriteBinary into this empty graph,
+// such that its KeyFunc and callbacks are used. The modes of the graph are set from the snapshot.
+// If reading fails, the graph is left partially filled.
+func (g *Graph) ReadBinary(r io.Reader, codec PayloadCodec) error {
+	if len(g.Nodes) > 0 {
+		return fmt.Errorf("cannot read a snapshot into a graph with nodes")
+	}
+
+	if codec == nil {
+		codec = GobCodec{}
+	}
+
+	br := bufio.NewReader(r)
+	dec := &binaryDecoder{r: br, h: crc32.NewIEEE(), codec: codec}
+
+	magic := make([]byte, len(binaryMagic))
+	if _, err := io.ReadFull(dec, magic); err != nil || string(magic) != binaryMagic {
+		return ErrInvalidFormat
+	}
+
+	version := dec.readUvarint()
+	if dec.err == nil && (version == 0 || version > binaryVersion) {
+		return ErrUnsupportedVersion
+	}
+
+	g.setBinaryFlags(dec.readUvarint())
+
+	// Self-loops created while they were allowed are kept, even if they are not allowed anymore
+	allowSelfLoops := g.AllowSelfLoops
+	g.AllowSelfLoops = true
+	defer func() {
+		g.AllowSelfLoops = allowSelfLoops
+	}()
+
+	count := dec.readUvarint()
+	var nodes []*Node
+	byID := make(map[uint64]*Node)
+	for i := uint64(0); i < count && dec.err == nil; i++ {
+		id := dec.readUvarint()
+		data := dec.readPayload()
+		region := dec.readPayload()
+		if dec.err != nil {
+			break
+		}
+
+		// Keys decoded from corrupt input may not be usable as map keys
+		if !isComparable(g.key(data)) || !isComparable(region) {
+			return ErrInvalidFormat
+		}
+
+		if _, hasID := byID[id]; hasID || id > math.MaxUint32 || g.Find(data) != nil {
+			return ErrInvalidFormat
+		}
+
+		n := g.NewNode(data)
+		n.ID = uint32(id)
+		if region != nil {
+			n.PutIntoRegion(region)
+		}
+		nodes = append(nodes, n)
+		byID[id] = n
+	}
+
+	for _, n := range nodes {
+		degree := dec.readUvarint()
+		for i := uint64(0); i < degree && dec.err == nil; i++ {
+			other, hasNode := byID[dec.readUvarint()]
+			kind := HardEdge
+			if version >= 2 {
+				kind = EdgeKind(dec.readUvarint())
+			}
+			var label interface{}
+			if version >= 3 {
+				label = dec.readPayload()
+			}
+			data := dec.readPayload()
+			if dec.err != nil {
+				break
+			}
+			if !hasNode || kind > OrderOnlyEdge || !isComparable(label) {
+				return ErrInvalidFormat
+			}
+
+			if _, err := n.connect(other, &EdgeOptions{Kind: kind, Label: label, Data: data}); err != nil {
+				return ErrInvalidFormat
+			}
+		}
+	}
+
+	if version >= 4 {
+		count := dec.readUvarint()
+		for i := uint64(0); i < count && dec.err == nil; i++ {
+			region := dec.readPayload()
+			parent := dec.readPayload()
+			if dec.err != nil {
+				break
+			}
+			if parent == nil || !isComparable(region) || !isComparable(parent) {
+				return ErrInvalidFormat
+			}
+
+			if err := g.SetRegionParent(region, parent); err != nil {
+				return ErrInvalidFormat
+			}
+		}
+	}
+
+	if dec.err != nil {
+		if dec.err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return dec.err
+	}
+
+	expected := dec.h.Sum32()
+	var sum [4]byte
+	if _, err := io.ReadFull(br, sum[:]); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	if binary.BigEndian.Uint32(sum[:]) != expected {
+		return ErrChecksumMismatch
+	}
+
+	return nil
+}
+
+func (g *Graph) binaryFlags() (flags uint64) {
+	if g.Multigraph {
+		flags |= binaryFlagMultigraph
+	}
+	if g.Undirected {
+		flags |= binaryFlagUndirected
+	}
+	if g.AllowSelfLoops {
+		flags |= binaryFlagSelfLoops
+	}
+
+	return
+}
+
+func (g *Graph) setBinaryFlags(flags uint64) {
+	g.Multigraph = flags&binaryFlagMultigraph != 0
+	g.Undirected = flags&binaryFlagUndirected != 0
+	g.AllowSelfLoops = flags&binaryFlagSelfLoops != 0
+}
+
+// binaryEncoder writes varints and payloads, remembering the first error.
+type binaryEncoder struct {
+	w     io.Writer
+	codec PayloadCodec
+	buf   [binary.MaxVarintLen64]byte
+	err   error
+}
+
+func (e *binaryEncoder) writeBytes(b []byte) {
+	if e.err != nil {
+		return
+	}
+
+	_, e.err = e.w.Write(b)
+}
+
+func (e *binaryEncoder) writeUvarint(v uint64) {
+	n := binary.PutUvarint(e.buf[:], v)
+	e.writeBytes(e.buf[:n])
+}
+
+func (e *binaryEncoder) writePayload(v interface{}) {
+	if e.err != nil {
+		return
+	}
+
+	if v == nil {
+		e.writeUvarint(0)
+		return
+	}
+
+	b, err := e.codec.Marshal(v)
+	if err != nil {
+		e.err = err
+		return
+	}
+
+	e.writeUvarint(uint64(len(b)) + 1)
+	e.writeBytes(b)
+}
+
+// binaryDecoder reads varints and payloads, feeding every consumed byte
+// to the checksum and remembering the first error.
+type binaryDecoder struct {
+	r     *bufio.Reader
+	h     hash.Hash32
+	codec PayloadCodec
+	err   error
+}
+
+func (d *binaryDecoder) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	d.h.Write(p[:n])
+	return n, err
+}
+
+func (d *binaryDecoder) ReadByte() (byte, error) {
+	b, err := d.r.ReadByte()
+	if err == nil {
+		d.h.Write([]byte{b})
+	}
+	return b, err
+}
+
+func (d *binaryDecoder) readUvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	v, err := binary.ReadUvarint(d)
+	if err != nil {
+		d.err = err
+	}
+
+	return v
+}
+
+func (d *binaryDecoder) readPayload() interface{} {
+	size := d.readUvarint()
+	if d.err != nil || size == 0 {
+		return nil
+	}
+
+	if size-1 > maxPayloadSize {
+		d.err = ErrInvalidFormat
+		return nil
+	}
+
+	b := make([]byte, size-1)
+	if _, err := io.ReadFull(d, b); err != nil {
+		d.err = err
+		return nil
+	}
+
+	v, err := d.codec.Unmarshal(b)
+	if err != nil {
+		d.err = err
+	}
+
+	return v
+}
@@ -0,0 +1,237 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"testing"
+)
+
+func TestGraph_binaryRoundTrip(t *testing.T) {
+	g := NewGraph()
+
+	n1 := g.NewNode("a").PutIntoRegion("r1")
+	n2 := g.NewNode("b").PutIntoRegion("r2")
+	n3 := g.NewNode("c")
+
+	n1.DependOn(n2).Data = 7
+	n1.DependOn(n3)
+	n2.DependOn(n3)
+
+	var buf bytes.Buffer
+	if err := g.WriteBinary(&buf, nil); err != nil {
+		t.Fatalf("Unable to write graph: %v", err)
+	}
+
+	g2, err := ReadBinary(&buf, nil)
+	if err != nil {
+		t.Fatalf("Unable to read graph: %v", err)
+	}
+
+	if g2.Size() != 3 || g2.NumberOfEdges() != 3 {
+		t.Errorf("Expected 3 nodes and 3 edges, got %v and %v", g2.Size(), g2.NumberOfEdges())
+	}
+
+	a, b := g2.Find("a"), g2.Find("b")
+	if a == nil || b == nil || a.ID != n1.ID || b.ID != n2.ID {
+		t.Fatalf("Nodes were not restored")
+	}
+
+	if a.Region != "r1" || b.Region != "r2" {
+		t.Errorf("Regions were not restored")
+	}
+
+	e := a.DependsOnAdjacent(b)
+	if e == nil || e.Data != 7 {
+		t.Errorf("Edge data was not restored")
+	}
+}
+
+func TestGraph_binaryRegionParents(t *testing.T) {
+	g := NewGraph()
+	g.SetRegionParent("pkg", "svc")
+	g.SetRegionParent("svc", "repo")
+	g.SetRegionParent(7, "repo")
+	g.NewNode("a").PutIntoRegion("pkg")
+
+	var buf, again bytes.Buffer
+	if err := g.WriteBinary(&buf, nil); err != nil {
+		t.Fatalf("Unable to write graph: %v", err)
+	}
+	g.WriteBinary(&again, nil)
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Errorf("Expected the same snapshot for the same graph")
+	}
+
+	g2, err := ReadBinary(&buf, nil)
+	if err != nil {
+		t.Fatalf("Unable to read graph: %v", err)
+	}
+
+	if len(g2.RegionParents) != 3 || g2.RegionParent("pkg") != "svc" || g2.RegionParent(7) != "repo" {
+		t.Errorf("The region parents were not restored, got %v", g2.RegionParents)
+	}
+	if len(g2.NodesUnder("repo")) != 1 || g2.RegionAtLevel("pkg", 0) != "repo" {
+		t.Errorf("The region hierarchy was not restored")
+	}
+
+	// A version 3 snapshot ends before the region parents
+	g2 = NewGraph()
+	g2.NewNode("a").PutIntoRegion("pkg")
+	buf.Reset()
+	g2.WriteBinary(&buf, nil)
+	b := buf.Bytes()
+	b = append([]byte(nil), b[:len(b)-5]...)
+	b[len(binaryMagic)] = 3
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc32.ChecksumIEEE(b))
+	b = append(b, sum[:]...)
+
+	g3, err := ReadBinary(bytes.NewReader(b), nil)
+	if err != nil || g3.Find("a").Region != "pkg" || len(g3.RegionParents) != 0 {
+		t.Errorf("Unable to read a version 3 snapshot: %v", err)
+	}
+}
+
+func TestGraph_binaryChecksum(t *testing.T) {
+	g := NewGraph()
+	g.NewNode(1).DependOn(g.NewNode(2))
+
+	var buf bytes.Buffer
+	if err := g.WriteBinary(&buf, nil); err != nil {
+		t.Fatalf("Unable to write graph: %v", err)
+	}
+
+	b := buf.Bytes()
+	b[len(b)-5] ^= 0xff
+
+	if _, err := ReadBinary(bytes.NewReader(b), nil); err == nil {
+		t.Errorf("A corrupt snapshot should not be readable")
+	}
+}
+
+func TestGraph_binaryCorruption(t *testing.T) {
+	g := NewGraph()
+	g.NewNode(1).DependOn(g.NewNode(2))
+
+	var buf bytes.Buffer
+	if err := g.WriteBinary(&buf, nil); err != nil {
+		t.Fatalf("Unable to write graph: %v", err)
+	}
+
+	snapshot := buf.Bytes()
+	for i := range snapshot {
+		for _, mask := range []byte{0x01, 0x02, 0x04, 0x80, 0xff} {
+			b := append([]byte(nil), snapshot...)
+			b[i] ^= mask
+			if _, err := ReadBinary(bytes.NewReader(b), nil); err == nil {
+				t.Errorf("Corrupting byte %v with %x should fail", i, mask)
+			}
+		}
+	}
+
+	g.Find(2).ID = g.Find(1).ID
+	buf.Reset()
+	g.WriteBinary(&buf, nil)
+	if _, err := ReadBinary(&buf, nil); err != ErrInvalidFormat {
+		t.Errorf("Expected ErrInvalidFormat for duplicate ids, got %v", err)
+	}
+}
+
+func TestGraph_binarySelfLoops(t *testing.T) {
+	g := NewGraph()
+	g.AllowSelfLoops = true
+	n := g.NewNode(1)
+	n.DependOn(n)
+	g.AllowSelfLoops = false
+
+	var buf bytes.Buffer
+	if err := g.WriteBinary(&buf, nil); err != nil {
+		t.Fatalf("Unable to write graph: %v", err)
+	}
+
+	read, err := ReadBinary(&buf, nil)
+	if err != nil {
+		t.Fatalf("Unable to read graph: %v", err)
+	}
+
+	if read.AllowSelfLoops || read.SizeEdges() != 1 || read.Find(1).DependsOnAdjacent(read.Find(1)) == nil {
+		t.Errorf("Expected the self-loop to be kept without allowing new ones")
+	}
+}
+
+func TestGraph_binaryVersion(t *testing.T) {
+	b := []byte(binaryMagic)
+	b = append(b, binaryVersion+1, 0)
+
+	if _, err := ReadBinary(bytes.NewReader(b), nil); err != ErrUnsupportedVersion {
+		t.Errorf("Expected ErrUnsupportedVersion, got %v", err)
+	}
+}
+
+func TestGraph_binaryMultigraph(t *testing.T) {
+	g := NewGraph()
+	g.Multigraph = true
+
+	n1 := g.NewNode(1)
+	n2 := g.NewNode(2)
+	n1.DependOnLabeled(n2, "import")
+	n1.DependOnKind(n2, OrderOnlyEdge)
+
+	var buf bytes.Buffer
+	if err := g.WriteBinary(&buf, nil); err != nil {
+		t.Fatalf("Unable to write graph: %v", err)
+	}
+
+	g2, err := ReadBinary(&buf, nil)
+	if err != nil {
+		t.Fatalf("Unable to read graph: %v", err)
+	}
+
+	if !g2.Multigraph || g2.NumberOfEdges() != 2 {
+		t.Fatalf("Expected a multigraph with 2 edges")
+	}
+
+	if e := g2.Find(1).EdgeTo(g2.Find(2), nil); e == nil || e.Kind != OrderOnlyEdge {
+		t.Errorf("The edge kind was not restored")
+	}
+
+	if g2.Find(1).EdgeTo(g2.Find(2), "import") == nil {
+		t.Errorf("The edge label was not restored")
+	}
+}
+
+func TestGraph_binaryKeyFunc(t *testing.T) {
+	keyFunc := func(data interface{}) interface{} {
+		return fmt.Sprint(data)
+	}
+
+	g := NewGraph()
+	g.KeyFunc = keyFunc
+	g.NewNode([]int{1, 2}).DependOn(g.NewNode([]int{3}))
+
+	var buf bytes.Buffer
+	if err := g.WriteBinary(&buf, nil); err != nil {
+		t.Fatalf("Unable to write graph: %v", err)
+	}
+
+	if _, err := ReadBinary(bytes.NewReader(buf.Bytes()), nil); err != ErrInvalidFormat {
+		t.Errorf("Expected ErrInvalidFormat without a KeyFunc, got %v", err)
+	}
+
+	read := NewGraph()
+	read.KeyFunc = keyFunc
+	if err := read.ReadBinary(&buf, nil); err != nil {
+		t.Fatalf("Unable to read graph: %v", err)
+	}
+
+	a, b := read.Find([]int{1, 2}), read.Find([]int{3})
+	if a == nil || b == nil || a.DependsOnAdjacent(b) == nil {
+		t.Errorf("Expected the nodes and their edge to be restored")
+	}
+
+	if err := read.ReadBinary(&buf, nil); err == nil {
+		t.Errorf("Expected an error reading into a graph with nodes")
+	}
+}
@@ -0,0 +1,177 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// EdgeSpec describes an edge for the Builder by the data of its nodes
+type EdgeSpec struct {
+	// From is the data of the dependent
+	From interface{}
+
+	// To is the data of the dependency
+	To interface{}
+
+	Kind  EdgeKind
+	Label interface{}
+	Data  interface{}
+}
+
+// BuildProblem is an edge refused by Builder.Build
+type BuildProblem struct {
+	Edge EdgeSpec
+	Err  error
+}
+
+func (p *BuildProblem) Error() string {
+	return fmt.Sprintf("edge %v -> %v: %v", p.Edge.From, p.Edge.To, p.Err)
+}
+
+func (p *BuildProblem) Unwrap() error {
+	return p.Err
+}
+
+// BuildError lists all the problems found by Builder.Build.
+// It matches the errors of its problems with errors.Is and errors.As.
+type BuildError struct {
+	Problems []error
+}
+
+func (e *BuildError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		messages[i] = p.Error()
+	}
+
+	return fmt.Sprintf("graph: %v problems building the graph: %v", len(e.Problems), strings.Join(messages, "; "))
+}
+
+func (e *BuildError) Unwrap() []error {
+	return e.Problems
+}
+
+// Builder collects batches of nodes and edges and inserts them into a graph at once.
+// The duplicate and self-loop checks are done in a single pass over the edges,
+// and the callbacks of the graph are not called.
+type Builder struct {
+	graph *Graph
+	nodes []interface{}
+	edges []EdgeSpec
+}
+
+// NewBuilder returns a builder adding to the given graph, or to a new graph if nil
+func NewBuilder(g *Graph) *Builder {
+	if g == nil {
+		g = NewGraph()
+	}
+
+	return &Builder{graph: g}
+}
+
+// AddNodes adds a batch of nodes by their data
+func (b *Builder) AddNodes(data ...interface{}) *Builder {
+	b.nodes = append(b.nodes, data...)
+	return b
+}
+
+// AddEdges adds a batch of edges. Their nodes are created as needed.
+func (b *Builder) AddEdges(edges ...EdgeSpec) *Builder {
+	b.edges = append(b.edges, edges...)
+	return b
+}
+
+// Build inserts the collected nodes and edges into the graph and returns it.
+// Self-loops, unless allowed, and duplicates are left out and reported together in a *BuildError.
+// So are new edges violating the rules of the graph, which are only left out if the rules are enforced.
+// Acyclic graphs are not supported, as every edge needs its own check, use AddEdge instead.
+func (b *Builder) Build() (*Graph, error) {
+	g := b.graph
+	if g.acyclic {
+		return g, errors.New("graph: the builder does not support acyclic graphs")
+	}
+
+	nodes, edges := b.nodes, b.edges
+	b.nodes, b.edges = nil, nil
+
+	resolve := func(data interface{}) *Node {
+		if n := g.Find(data); n != nil {
+			return n
+		}
+		return g.createNode(data)
+	}
+
+	for _, data := range nodes {
+		resolve(data)
+	}
+
+	// Resolve the nodes and grow their edges once
+	ends := make([][2]*Node, len(edges))
+	degrees := make(map[*Node]int)
+	for i, spec := range edges {
+		from, to := resolve(spec.From), resolve(spec.To)
+		ends[i] = [2]*Node{from, to}
+		degrees[from]++
+		if to != from {
+			degrees[to]++
+		}
+	}
+	for n, degree := range degrees {
+		grown := make([]*Edge, len(n.Edges), len(n.Edges)+degree)
+		copy(grown, n.Edges)
+		n.Edges = grown
+	}
+
+	var problems []error
+	inserted := false
+	for i, spec := range edges {
+		from, to := ends[i][0], ends[i][1]
+		if from == to && !g.AllowSelfLoops {
+			problems = append(problems, &BuildProblem{spec, ErrSelfLoop})
+			continue
+		}
+
+		if from.duplicateOf(to, spec.Label) != nil {
+			problems = append(problems, &BuildProblem{spec, ErrDuplicateEdge})
+			continue
+		}
+
+		edge := &Edge{
+			Source:      from,
+			Destination: to,
+			Data:        spec.Data,
+			Kind:        spec.Kind,
+			Label:       spec.Label,
+		}
+		edge.updateCrossRegion()
+
+		if g.Rules != nil {
+			violations := g.Rules.checkNewEdge(g, edge)
+			for _, v := range violations {
+				problems = append(problems, &BuildProblem{spec, fmt.Errorf("%w: %v", ErrRuleViolation, v)})
+			}
+			if g.Rules.Enforce && len(violations) > 0 {
+				continue
+			}
+		}
+
+		g.indexEdge(edge)
+		from.Edges = append(from.Edges, edge)
+		if to != from {
+			to.Edges = append(to.Edges, edge)
+		}
+		inserted = true
+	}
+
+	// The edges were not inserted in order, see Order
+	if inserted {
+		g.order = nil
+	}
+
+	if len(problems) > 0 {
+		return g, &BuildError{Problems: problems}
+	}
+
+	return g, nil
+}
@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	created := 0
+	g := NewGraph()
+	g.OnEdgeCreated = func(*Edge) {
+		created++
+	}
+	g.NewNode("a").DependOn(g.NewNode("b"))
+
+	g, err := NewBuilder(g).
+		AddNodes("c", "d", "c").
+		AddEdges(
+			EdgeSpec{From: "c", To: "a", Data: 1},
+			EdgeSpec{From: "d", To: "c", Kind: SoftEdge},
+			EdgeSpec{From: "a", To: "b"},
+			EdgeSpec{From: "d", To: "d"},
+			EdgeSpec{From: "d", To: "c"},
+			EdgeSpec{From: "e", To: "d"},
+		).
+		Build()
+
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) || len(buildErr.Problems) != 3 {
+		t.Fatalf("Expected 3 problems, got %v", err)
+	}
+	if !errors.Is(err, ErrSelfLoop) || !errors.Is(err, ErrDuplicateEdge) {
+		t.Errorf("Expected a self-loop and duplicates, got %v", err)
+	}
+
+	var problem *BuildProblem
+	if !errors.As(buildErr.Problems[0], &problem) || problem.Edge.From != "a" {
+		t.Errorf("Expected the duplicate from a to b first, got %v", buildErr.Problems[0])
+	}
+
+	if g.Size() != 5 || g.SizeEdges() != 4 || created != 1 {
+		t.Errorf("Expected 5 nodes and 4 edges without callbacks, got %v, %v and %v callbacks", g.Size(), g.SizeEdges(), created)
+	}
+
+	c, d := g.Find("c"), g.Find("d")
+	if e := d.DependsOnAdjacent(c); e == nil || e.Kind != SoftEdge || c.DependsOnAdjacent(g.Find("a")).Data != 1 {
+		t.Errorf("Expected the edges to keep their kind and data")
+	}
+
+	if _, err := g.Order(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	checkOrder(t, g)
+	checkEdgeIndex(t, g)
+}
+
+func TestBuilder_rules(t *testing.T) {
+	g := NewGraph()
+	g.NewNode("a").PutIntoRegion("core")
+	g.NewNode("b").PutIntoRegion("ui")
+	g.Rules = NewRuleSet().Deny("core", "ui")
+
+	_, err := NewBuilder(g).AddEdges(EdgeSpec{From: "a", To: "b"}).Build()
+	if !errors.Is(err, ErrRuleViolation) || g.SizeEdges() != 1 {
+		t.Errorf("Expected the violating edge to be inserted and reported, got %v", err)
+	}
+
+	// Violations existing before the batch are not reported again
+	g.NewNode("c").PutIntoRegion("ui")
+	if _, err := NewBuilder(g).AddEdges(EdgeSpec{From: "b", To: "c"}).Build(); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	g.Rules.Enforce = true
+	_, err = NewBuilder(g).AddEdges(EdgeSpec{From: "a", To: "c"}).Build()
+	var problem *BuildProblem
+	if !errors.As(err, &problem) || problem.Edge.To != "c" || g.Find("a").DependsOnAdjacent(g.Find("c")) != nil {
+		t.Errorf("Expected the violating edge to be refused and reported, got %v", err)
+	}
+
+	if err := g.EnableAcyclic(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if _, err := NewBuilder(g).Build(); err == nil {
+		t.Errorf("Expected acyclic graphs to be refused")
+	}
+}
@@ -0,0 +1,397 @@
+package graph
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Partition assigns each node to a numbered group
+type Partition map[*Node]int
+
+// nodes returns the nodes of the partition ordered by id
+func (p Partition) nodes() []*Node {
+	nodes := make([]*Node, 0, len(p))
+	for n := range p {
+		nodes = append(nodes, n)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	return nodes
+}
+
+// Groups returns the members of each group, indexed by group number
+func (p Partition) Groups() [][]*Node {
+	var groups [][]*Node
+	for _, n := range p.nodes() {
+		c := p[n]
+		for len(groups) <= c {
+			groups = append(groups, nil)
+		}
+		groups[c] = append(groups[c], n)
+	}
+
+	return groups
+}
+
+// Apply puts every node into the region returned by region for its group.
+// If region is nil, the group number is used as the region.
+func (p Partition) Apply(region func(int) interface{}) {
+	for _, n := range p.nodes() {
+		if region != nil {
+			n.PutIntoRegion(region(p[n]))
+		} else {
+			n.PutIntoRegion(p[n])
+		}
+	}
+}
+
+// normalize renumbers the groups from zero in the order of the node ids
+func (p Partition) normalize() Partition {
+	numbers := make(map[int]int)
+	normalized := make(Partition, len(p))
+	for _, n := range p.nodes() {
+		c, hasNumber := numbers[p[n]]
+		if !hasNumber {
+			c = len(numbers)
+			numbers[p[n]] = c
+		}
+		normalized[n] = c
+	}
+
+	return normalized
+}
+
+// RegionPartition returns the partition given by the regions of the graph.
+// Each node without a region forms a group of its own.
+func (g *Graph) RegionPartition() Partition {
+	p := make(Partition, len(g.Nodes))
+	regions := make(map[interface{}]int)
+	for _, n := range g.sortedNodes() {
+		if n.Region == nil {
+			p[n] = len(p)
+			continue
+		}
+
+		c, hasRegion := regions[n.Region]
+		if !hasRegion {
+			c = len(p)
+			regions[n.Region] = c
+		}
+		p[n] = c
+	}
+
+	return p.normalize()
+}
+
+// weightedGraph is an undirected weighted view of a graph used by the community algorithms
+type weightedGraph struct {
+	nodes     []*Node
+	neighbors [][]weightedNeighbor
+	self      []float64
+	degree    []float64
+	total     float64
+}
+
+type weightedNeighbor struct {
+	node   int
+	weight float64
+}
+
+func newWeightedGraph(g *Graph) *weightedGraph {
+	nodes := g.sortedNodes()
+	index := make(map[*Node]int, len(nodes))
+	for i, n := range nodes {
+		index[n] = i
+	}
+
+	wg := &weightedGraph{
+		nodes:     nodes,
+		neighbors: make([][]weightedNeighbor, len(nodes)),
+		self:      make([]float64, len(nodes)),
+		degree:    make([]float64, len(nodes)),
+	}
+
+	for i, n := range nodes {
+		for _, e := range n.Edges {
+			if e.Source != n {
+				continue
+			}
+
+			wg.addEdge(i, index[e.Destination], 1)
+		}
+	}
+
+	return wg
+}
+
+func (wg *weightedGraph) addEdge(u, v int, weight float64) {
+	if u == v {
+		wg.self[u] += weight
+	} else {
+		wg.neighbors[u] = append(wg.neighbors[u], weightedNeighbor{v, weight})
+		wg.neighbors[v] = append(wg.neighbors[v], weightedNeighbor{u, weight})
+	}
+
+	wg.degree[u] += weight
+	wg.degree[v] += weight
+	wg.total += weight
+}
+
+// modularity returns the modularity of the given assignment of the weighted graph nodes
+func (wg *weightedGraph) modularity(community []int) float64 {
+	if wg.total == 0 {
+		return 0
+	}
+
+	internal := make(map[int]float64)
+	degrees := make(map[int]float64)
+	for i := range wg.neighbors {
+		c := community[i]
+		degrees[c] += wg.degree[i]
+		internal[c] += wg.self[i]
+		for _, nb := range wg.neighbors[i] {
+			if nb.node > i && community[nb.node] == c {
+				internal[c] += nb.weight
+			}
+		}
+	}
+
+	q := 0.0
+	for c, d := range degrees {
+		q += internal[c]/wg.total - (d/(2*wg.total))*(d/(2*wg.total))
+	}
+
+	return q
+}
+
+// Modularity returns the modularity of the partition, treating the edges as undirected.
+// Higher values mean denser connections within groups than between them.
+func (g *Graph) Modularity(p Partition) float64 {
+	wg := newWeightedGraph(g)
+	community := make([]int, len(wg.nodes))
+	for i, n := range wg.nodes {
+		community[i] = p[n]
+	}
+
+	return wg.modularity(community)
+}
+
+// gainEpsilon guards the local moves against rounding errors
+const gainEpsilon = 1e-12
+
+// localMoves moves nodes between communities as long as the modularity increases.
+// It returns true if any node was moved.
+func (wg *weightedGraph) localMoves(community []int) bool {
+	tot := make(map[int]float64)
+	for i, c := range community {
+		tot[c] += wg.degree[i]
+	}
+
+	moved := false
+	for improved := true; improved; {
+		improved = false
+		for i := range wg.neighbors {
+			current := community[i]
+			k := wg.degree[i]
+			tot[current] -= k
+
+			links := make(map[int]float64)
+			var candidates []int
+			for _, nb := range wg.neighbors[i] {
+				c := community[nb.node]
+				if _, seen := links[c]; !seen {
+					candidates = append(candidates, c)
+				}
+				links[c] += nb.weight
+			}
+
+			best := current
+			bestGain := links[current] - tot[current]*k/(2*wg.total)
+			for _, c := range candidates {
+				gain := links[c] - tot[c]*k/(2*wg.total)
+				if gain > bestGain+gainEpsilon {
+					best, bestGain = c, gain
+				}
+			}
+
+			tot[best] += k
+			if best != current {
+				community[i] = best
+				improved = true
+				moved = true
+			}
+		}
+	}
+
+	return moved
+}
+
+// aggregate returns a graph with one node per community
+func (wg *weightedGraph) aggregate(community []int) (*weightedGraph, []int) {
+	numbers := make(map[int]int)
+	renumbered := make([]int, len(community))
+	for i, c := range community {
+		n, hasNumber := numbers[c]
+		if !hasNumber {
+			n = len(numbers)
+			numbers[c] = n
+		}
+		renumbered[i] = n
+	}
+
+	ag := &weightedGraph{
+		neighbors: make([][]weightedNeighbor, len(numbers)),
+		self:      make([]float64, len(numbers)),
+		degree:    make([]float64, len(numbers)),
+	}
+
+	weights := make(map[[2]int]float64)
+	for i := range wg.neighbors {
+		ci := renumbered[i]
+		ag.addEdge(ci, ci, wg.self[i])
+		for _, nb := range wg.neighbors[i] {
+			if nb.node < i {
+				continue
+			}
+
+			cj := renumbered[nb.node]
+			if ci == cj {
+				ag.addEdge(ci, ci, nb.weight)
+			} else {
+				pair := [2]int{ci, cj}
+				if cj < ci {
+					pair = [2]int{cj, ci}
+				}
+				weights[pair] += nb.weight
+			}
+		}
+	}
+
+	pairs := make([][2]int, 0, len(weights))
+	for pair := range weights {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	for _, pair := range pairs {
+		ag.addEdge(pair[0], pair[1], weights[pair])
+	}
+
+	return ag, renumbered
+}
+
+// Louvain detects communities using the Louvain method, treating the edges as undirected.
+func (g *Graph) Louvain() Partition {
+	wg := newWeightedGraph(g)
+	assignment := make([]int, len(wg.nodes))
+	for i := range assignment {
+		assignment[i] = i
+	}
+
+	current := wg
+	for current.total > 0 {
+		community := make([]int, len(current.neighbors))
+		for i := range community {
+			community[i] = i
+		}
+
+		if !current.localMoves(community) {
+			break
+		}
+
+		var renumbered []int
+		current, renumbered = current.aggregate(community)
+		for i, c := range assignment {
+			assignment[i] = renumbered[c]
+		}
+	}
+
+	p := make(Partition, len(wg.nodes))
+	for i, n := range wg.nodes {
+		p[n] = assignment[i]
+	}
+
+	return p.normalize()
+}
+
+// LabelPropagation detects communities by letting every node adopt the label
+// most common among its neighbors, treating the edges as undirected.
+// Nodes are visited in random order and ties are broken randomly, using the given seed.
+// It stops when no labels change or after maxIterations rounds, 100 if zero.
+func (g *Graph) LabelPropagation(maxIterations int, seed int64) Partition {
+	if maxIterations <= 0 {
+		maxIterations = 100
+	}
+
+	rnd := rand.New(rand.NewSource(seed))
+	wg := newWeightedGraph(g)
+	labels := make([]int, len(wg.nodes))
+	order := make([]int, len(wg.nodes))
+	for i := range labels {
+		labels[i] = i
+		order[i] = i
+	}
+
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		rnd.Shuffle(len(order), func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+
+		changed := false
+		for _, i := range order {
+			if len(wg.neighbors[i]) == 0 {
+				continue
+			}
+
+			weights := make(map[int]float64)
+			var candidates []int
+			for _, nb := range wg.neighbors[i] {
+				label := labels[nb.node]
+				if _, seen := weights[label]; !seen {
+					candidates = append(candidates, label)
+				}
+				weights[label] += nb.weight
+			}
+
+			maxWeight := 0.0
+			for _, w := range weights {
+				if w > maxWeight {
+					maxWeight = w
+				}
+			}
+
+			// Keep the current label if it is among the most common ones
+			if weights[labels[i]] == maxWeight {
+				continue
+			}
+
+			var best []int
+			for _, label := range candidates {
+				if weights[label] == maxWeight {
+					best = append(best, label)
+				}
+			}
+
+			labels[i] = best[rnd.Intn(len(best))]
+			changed = true
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	p := make(Partition, len(wg.nodes))
+	for i, n := range wg.nodes {
+		p[n] = labels[i]
+	}
+
+	return p.normalize()
+}
@@ -0,0 +1,54 @@
+package graph
+
+import "testing"
+
+// newCliquesGraph returns two cliques of four nodes connected by a single edge
+func newCliquesGraph() *Graph {
+	g := NewGraph()
+
+	var nodes []*Node
+	for i := 0; i < 8; i++ {
+		nodes = append(nodes, g.NewNode(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		for j := i + 1; j < 8; j++ {
+			if i/4 == j/4 {
+				nodes[i].DependOn(nodes[j])
+			}
+		}
+	}
+	nodes[3].DependOn(nodes[4])
+
+	return g
+}
+
+func TestGraph_louvain(t *testing.T) {
+	g := newCliquesGraph()
+
+	p := g.Louvain()
+	groups := p.Groups()
+	if len(groups) != 2 || len(groups[0]) != 4 || len(groups[1]) != 4 {
+		t.Fatalf("Expected two groups of four nodes, got %v", groups)
+	}
+
+	if g.Modularity(p) <= g.Modularity(g.RegionPartition()) {
+		t.Errorf("The detected communities should have a higher modularity than no regions")
+	}
+
+	p.Apply(func(c int) interface{} {
+		return c + 10
+	})
+	if len(g.Regions[10]) != 4 || len(g.Regions[11]) != 4 {
+		t.Errorf("The partition was not applied to the regions")
+	}
+}
+
+func TestGraph_labelPropagation(t *testing.T) {
+	g := newCliquesGraph()
+
+	groups := g.LabelPropagation(0, 1).Groups()
+	if len(groups) != 2 || len(groups[0]) != 4 || len(groups[1]) != 4 {
+		t.Errorf("Expected two groups of four nodes, got %v", groups)
+	}
+}
@@ -0,0 +1,149 @@
+package graph
+
+import (
+	"io"
+	"reflect"
+	"strings"
+)
+
+// EdgeChange describes an edge whose data or kind differs between two graphs
+type EdgeChange struct {
+	Old *Edge
+	New *Edge
+}
+
+// RegionMove describes a node that changed region between two graphs
+type RegionMove struct {
+	Old  *Node
+	New  *Node
+	From interface{}
+	To   interface{}
+}
+
+// GraphDiff is the difference between two graphs.
+// Removed nodes and edges belong to the old graph, added ones to the new graph.
+type GraphDiff struct {
+	AddedNodes   []*Node
+	RemovedNodes []*Node
+	AddedEdges   []*Edge
+	RemovedEdges []*Edge
+	ChangedEdges []EdgeChange
+	RegionMoves  []RegionMove
+}
+
+// edgeEnds identifies an edge by the keys of its nodes and its label
+type edgeEnds struct {
+	source, destination, label interface{}
+}
+
+func outboundEdges(g *Graph) (map[edgeEnds]*Edge, []edgeEnds) {
+	edges := make(map[edgeEnds]*Edge)
+	var order []edgeEnds
+	for _, n := range g.sortedNodes() {
+		for _, e := range n.Edges {
+			if e.Source != n {
+				continue
+			}
+
+			ends := edgeEnds{g.key(e.Source.Data), g.key(e.Destination.Data), e.Label}
+			edges[ends] = e
+			order = append(order, ends)
+		}
+	}
+
+	return edges, order
+}
+
+// Diff compares two graphs, matching nodes by their keys and edges by their nodes and label.
+func Diff(a, b *Graph) *GraphDiff {
+	d := &GraphDiff{}
+
+	for _, n := range a.sortedNodes() {
+		if b.Find(n.Data) == nil {
+			d.RemovedNodes = append(d.RemovedNodes, n)
+		}
+	}
+
+	for _, n := range b.sortedNodes() {
+		old := a.Find(n.Data)
+		if old == nil {
+			d.AddedNodes = append(d.AddedNodes, n)
+			continue
+		}
+
+		if !reflect.DeepEqual(old.Region, n.Region) {
+			d.RegionMoves = append(d.RegionMoves, RegionMove{old, n, old.Region, n.Region})
+		}
+	}
+
+	aEdges, aOrder := outboundEdges(a)
+	bEdges, bOrder := outboundEdges(b)
+
+	for _, ends := range aOrder {
+		if _, hasEdge := bEdges[ends]; !hasEdge {
+			d.RemovedEdges = append(d.RemovedEdges, aEdges[ends])
+		}
+	}
+
+	for _, ends := range bOrder {
+		e := bEdges[ends]
+		old, hasEdge := aEdges[ends]
+		if !hasEdge {
+			d.AddedEdges = append(d.AddedEdges, e)
+			continue
+		}
+
+		if old.Kind != e.Kind || !reflect.DeepEqual(old.Data, e.Data) {
+			d.ChangedEdges = append(d.ChangedEdges, EdgeChange{old, e})
+		}
+	}
+
+	return d
+}
+
+// IsEmpty returns true if the graphs are equal
+func (d *GraphDiff) IsEmpty() bool {
+	return len(d.AddedNodes) == 0 && len(d.RemovedNodes) == 0 &&
+		len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0 &&
+		len(d.ChangedEdges) == 0 && len(d.RegionMoves) == 0
+}
+
+// Fprint writes the difference to w, one change per line.
+// Lines are prefixed with + for additions, - for removals, ~ for changed edges and > for region moves.
+func (d *GraphDiff) Fprint(w io.Writer) error {
+	ew := &errWriter{w: w}
+
+	for _, n := range d.RemovedNodes {
+		ew.printf("- node %v\n", n.graph.label(n))
+	}
+	for _, n := range d.AddedNodes {
+		ew.printf("+ node %v\n", n.graph.label(n))
+	}
+	for _, e := range d.RemovedEdges {
+		ew.printf("- edge %v -> %v\n", e.Source.graph.label(e.Source), e.Source.graph.label(e.Destination))
+	}
+	for _, e := range d.AddedEdges {
+		ew.printf("+ edge %v -> %v\n", e.Source.graph.label(e.Source), e.Source.graph.label(e.Destination))
+	}
+	for _, c := range d.ChangedEdges {
+		source, destination := c.New.Source.graph.label(c.New.Source), c.New.Source.graph.label(c.New.Destination)
+		if c.Old.Kind != c.New.Kind {
+			ew.printf("~ edge %v -> %v: %v => %v\n", source, destination, c.Old.Kind, c.New.Kind)
+		}
+		if !reflect.DeepEqual(c.Old.Data, c.New.Data) {
+			ew.printf("~ edge %v -> %v: %v => %v\n", source, destination, c.Old.Data, c.New.Data)
+		}
+	}
+	for _, m := range d.RegionMoves {
+		ew.printf("> node %v: %v => %v\n", m.New.graph.label(m.New), m.From, m.To)
+	}
+
+	return ew.err
+}
+
+// String returns the textual difference
+func (d *GraphDiff) String() string {
+	var sb strings.Builder
+	d.Fprint(&sb)
+	return sb.String()
+}
@@ -0,0 +1,36 @@
+package graph
+
+import "testing"
+
+func TestGraph_diff(t *testing.T) {
+	a := NewGraph()
+	a1 := a.NewNode("a").PutIntoRegion(1)
+	a2 := a.NewNode("b")
+	a3 := a.NewNode("c")
+	a1.DependOn(a2).Data = 1
+	a1.DependOn(a3)
+	a2.DependOn(a3)
+
+	b := NewGraph()
+	b1 := b.NewNode("a").PutIntoRegion(2)
+	b2 := b.NewNode("b")
+	b4 := b.NewNode("d")
+	b1.DependOn(b2).Data = 2
+	b2.DependOn(b4)
+
+	d := Diff(a, b)
+	expected := "- node c\n" +
+		"+ node d\n" +
+		"- edge a -> c\n" +
+		"- edge b -> c\n" +
+		"+ edge b -> d\n" +
+		"~ edge a -> b: 1 => 2\n" +
+		"> node a: 1 => 2\n"
+	if d.String() != expected {
+		t.Errorf("Expected\n%v\ngot\n%v", expected, d.String())
+	}
+
+	if !Diff(a, a).IsEmpty() {
+		t.Errorf("A graph should not differ from itself")
+	}
+}
@@ -5,7 +5,96 @@ type Edge struct {
 	Source      *Node
 	Destination *Node
 	Data        interface{}
+
+	// Kind is the kind of dependency, HardEdge by default
+	Kind EdgeKind
+
+	// Label distinguishes parallel edges in multigraph mode
+	Label interface{}
+
+	// CrossRegion is true if the source and destination are in different regions.
+	// It is maintained by the graph as edges are created and nodes change region.
 	CrossRegion bool
+
+	// attrs are the attributes of the edge, created on demand
+	attrs *Attributes
+}
+
+// EdgeKind describes the meaning of a dependency
+type EdgeKind uint8
+
+const (
+	// HardEdge orders the dependency first and invalidates the dependent when the dependency changes
+	HardEdge EdgeKind = iota
+	// SoftEdge invalidates the dependent, but imposes no order and is ignored by cycle detection
+	SoftEdge
+	// OrderOnlyEdge orders the dependency first, but does not invalidate the dependent
+	OrderOnlyEdge
+)
+
+// Orders returns true if edges of this kind constrain the topological order
+func (k EdgeKind) Orders() bool {
+	return k != SoftEdge
+}
+
+// Invalidates returns true if a change to the dependency invalidates the dependent
+func (k EdgeKind) Invalidates() bool {
+	return k != OrderOnlyEdge
+}
+
+func (k EdgeKind) String() string {
+	switch k {
+	case HardEdge:
+		return "hard"
+	case SoftEdge:
+		return "soft"
+	case OrderOnlyEdge:
+		return "order-only"
+	default:
+		return "unknown"
+	}
+}
+
+// hasKind returns true if the kind is one of the given kinds
+func (k EdgeKind) hasKind(kinds []EdgeKind) bool {
+	for _, kind := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// updateCrossRegion sets CrossRegion if the nodes of the edge are in different regions.
+func (e *Edge) updateCrossRegion() {
+	e.CrossRegion = e.Source.Region != e.Destination.Region
+}
+
+// CrossRegionAt returns true if the source and destination are in different
+// regions at the given level of the region hierarchy.
+func (e *Edge) CrossRegionAt(level int) bool {
+	g := e.Source.graph
+	return g.RegionAtLevel(e.Source.Region, level) != g.RegionAtLevel(e.Destination.Region, level)
+}
+
+// Other returns the node at the other end of the edge
+func (e *Edge) Other(n *Node) *Node {
+	if e.Source == n {
+		return e.Destination
+	}
+
+	return e.Source
+}
+
+// connects returns true if the edge goes from one node to the other.
+// In undirected mode, the direction is ignored.
+func (e *Edge) connects(from, to *Node) bool {
+	if e.Source == from && e.Destination == to {
+		return true
+	}
+
+	return from.graph.Undirected && e.Source == to && e.Destination == from
 }
 
 // Remove will remove an edge.
@@ -20,12 +109,43 @@ func CreateLink(from, to *Node) *Edge {
 	return from.DependOn(to)
 }
 
+// RemoveEdge removes the edges between the two nodes, in both directions.
 func RemoveEdge(node1, node2 *Node) {
-	for _, edge := range node1.Edges {
-		if edge.Source != node2 && edge.Destination != node2 {
-			continue
-		}
+	g := node1.graph
+	edges := append([]*Edge(nil), g.edges[edgeKey{node1, node2}]...)
+	if node1 != node2 {
+		edges = append(edges, g.edges[edgeKey{node2, node1}]...)
+	}
 
+	for _, edge := range edges {
 		edge.Remove()
 	}
 }
+
+// Direction selects which edges of a node are followed.
+type Direction uint8
+
+const (
+	// Outbound follows the edges from a node to its dependencies
+	Outbound Direction = iota
+	// Inbound follows the edges from a node to its dependents
+	Inbound
+)
+
+// follows returns true if the edge leads away from the node in the given direction.
+func (d Direction) follows(node *Node, edge *Edge) bool {
+	if d == Inbound {
+		return edge.Destination == node
+	}
+
+	return edge.Source == node
+}
+
+// next returns the node at the other end of the edge in the given direction.
+func (d Direction) next(edge *Edge) *Node {
+	if d == Inbound {
+		return edge.Source
+	}
+
+	return edge.Destination
+}
@@ -0,0 +1,85 @@
+package graph
+
+// edgeKey identifies the edges from a source to a destination
+type edgeKey struct {
+	source, destination *Node
+}
+
+// indexEdge adds the edge to the edge index and the region edge counts of the graph
+func (g *Graph) indexEdge(e *Edge) {
+	if g.edges == nil {
+		g.edges = make(map[edgeKey][]*Edge)
+	}
+
+	key := edgeKey{e.Source, e.Destination}
+	g.edges[key] = append(g.edges[key], e)
+	g.countRegionEdge(e, 1)
+}
+
+// unindexEdge removes the edge from the edge index and the region edge counts of the graph,
+// if it is there
+func (g *Graph) unindexEdge(e *Edge) {
+	key := edgeKey{e.Source, e.Destination}
+	edges := g.edges[key]
+	for i, edge := range edges {
+		if edge != e {
+			continue
+		}
+
+		if len(edges) == 1 {
+			delete(g.edges, key)
+		} else {
+			g.edges[key] = append(edges[:i:i], edges[i+1:]...)
+		}
+		g.countRegionEdge(e, -1)
+		return
+	}
+}
+
+// isIndexed returns true if the edge is in the edge index of the graph
+func (g *Graph) isIndexed(e *Edge) bool {
+	for _, edge := range g.edges[edgeKey{e.Source, e.Destination}] {
+		if edge == e {
+			return true
+		}
+	}
+
+	return false
+}
+
+// edgesBetween returns the edges from the source to the destination, in the order they were created.
+// In undirected mode, the edges in the other direction follow.
+// The returned slice must not be modified.
+func (g *Graph) edgesBetween(source, destination *Node) []*Edge {
+	edges := g.edges[edgeKey{source, destination}]
+	if !g.Undirected || source == destination {
+		return edges
+	}
+
+	reverse := g.edges[edgeKey{destination, source}]
+	if len(reverse) == 0 {
+		return edges
+	}
+
+	return append(edges[:len(edges):len(edges)], reverse...)
+}
+
+// DependOnAll inserts the other nodes as hard dependencies for this node.
+// It returns the new or existing edge for every other node, leaving out refused edges.
+// Duplicates are looked up in the edge index, so the time is proportional to the number of other nodes.
+func (n *Node) DependOnAll(others ...*Node) []*Edge {
+	if free := cap(n.Edges) - len(n.Edges); free < len(others) {
+		edges := make([]*Edge, len(n.Edges), len(n.Edges)+len(others))
+		copy(edges, n.Edges)
+		n.Edges = edges
+	}
+
+	edges := make([]*Edge, 0, len(others))
+	for _, other := range others {
+		if e := n.DependOn(other); e != nil {
+			edges = append(edges, e)
+		}
+	}
+
+	return edges
+}
@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// checkEdgeIndex fails if the index does not hold exactly the outbound edges of the nodes
+func checkEdgeIndex(t *testing.T, g *Graph) {
+	t.Helper()
+
+	count := 0
+	for _, n := range g.Nodes {
+		for _, e := range n.Edges {
+			if e.Source != n {
+				continue
+			}
+
+			count++
+			found := false
+			for _, indexed := range g.edges[edgeKey{e.Source, e.Destination}] {
+				found = found || indexed == e
+			}
+			if !found {
+				t.Fatalf("The edge from %v to %v is not indexed", e.Source, e.Destination)
+			}
+		}
+	}
+
+	indexed := 0
+	for _, edges := range g.edges {
+		indexed += len(edges)
+	}
+	if indexed != count {
+		t.Fatalf("Expected %v indexed edges, got %v", count, indexed)
+	}
+}
+
+func TestGraph_edgeIndex(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	g := NewGraph()
+	g.Multigraph = true
+	g.AllowSelfLoops = true
+
+	for i := 0; i < 2000; i++ {
+		n1, n2 := g.NewNode(rnd.Intn(20)), g.NewNode(rnd.Intn(20))
+		switch rnd.Intn(6) {
+		case 0:
+			n1.RemoveDependency(n2)
+		case 1:
+			RemoveEdge(n1, n2)
+		case 2:
+			if e := n1.EdgeTo(n2, nil); e != nil {
+				e.Remove()
+			}
+		default:
+			n1.DependOnLabeled(n2, rnd.Intn(2))
+		}
+	}
+
+	checkEdgeIndex(t, g)
+}
+
+func TestNode_dependOnAll(t *testing.T) {
+	g := NewGraph()
+	n1 := g.NewNode(1)
+	n2 := g.NewNode(2)
+	n3 := g.NewNode(3)
+
+	existing := n1.DependOn(n2)
+	edges := n1.DependOnAll(n2, n3, n3, n1)
+	if len(edges) != 3 || edges[0] != existing || edges[1] != edges[2] {
+		t.Fatalf("Expected the existing edge and one new edge, got %v", edges)
+	}
+
+	if len(n1.Edges) != 2 || n1.DependsOnAdjacent(n3) != edges[1] {
+		t.Errorf("Expected 2 edges, got %v", n1.Edges)
+	}
+
+	RemoveEdge(n3, n1)
+	if n1.DependsOnAdjacent(n3) != nil || len(n3.Edges) != 0 {
+		t.Errorf("Expected the edge to be removed")
+	}
+	checkEdgeIndex(t, g)
+}
+
+func TestGraph_edgeIndexUndirected(t *testing.T) {
+	g := NewGraph()
+	g.Undirected = true
+	n1 := g.NewNode(1)
+	n2 := g.NewNode(2)
+
+	e := n1.Connect(n2)
+	if n2.Connect(n1) != e || n2.DependsOnAdjacent(n1) != e || len(n2.EdgesTo(n1)) != 1 {
+		t.Errorf("Expected the edge to be found in both directions")
+	}
+
+	n2.RemoveDependency(n1)
+	if n1.DependsOnAdjacent(n2) != nil || len(n1.Edges) != 0 {
+		t.Errorf("Expected the edge to be removed")
+	}
+	checkEdgeIndex(t, g)
+}
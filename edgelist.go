@@ -0,0 +1,299 @@
+package graph
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// NodeKey selects how nodes are identified in edge lists and adjacency matrices.
+type NodeKey uint8
+
+const (
+	// KeyByID identifies nodes by Node.ID
+	KeyByID NodeKey = iota
+	// KeyByLabel identifies nodes by the string produced by the NodeStringer
+	KeyByLabel
+)
+
+// TableOptions configures the edge list and adjacency matrix readers and writers.
+type TableOptions struct {
+	// Comma is the field delimiter, ',' if zero. Use '\t' for TSV.
+	Comma rune
+
+	// Key decides how nodes are identified
+	Key NodeKey
+
+	// Header writes, or skips when reading, a header line in edge lists
+	Header bool
+
+	// Weights maps the weight column, or the matrix cells, to Edge.Data
+	Weights bool
+
+	// FormatWeight formats Edge.Data, fmt.Sprint if nil
+	FormatWeight func(interface{}) string
+
+	// ParseWeight parses a weight into Edge.Data, a float64 if nil
+	ParseWeight func(string) (interface{}, error)
+
+	// NodeData returns the data of a node created for a key missing from the graph.
+	// If nil, the data is the uint32 id or the label. Set it when the graph has a KeyFunc,
+	// as the data of new nodes is passed to it.
+	NodeData func(key string) interface{}
+}
+
+func (o *TableOptions) withDefaults() *TableOptions {
+	opts := TableOptions{}
+	if o != nil {
+		opts = *o
+	}
+
+	if opts.Comma == 0 {
+		opts.Comma = ','
+	}
+
+	if opts.FormatWeight == nil {
+		opts.FormatWeight = func(v interface{}) string {
+			return fmt.Sprint(v)
+		}
+	}
+
+	if opts.ParseWeight == nil {
+		opts.ParseWeight = func(s string) (interface{}, error) {
+			return strconv.ParseFloat(s, 64)
+		}
+	}
+
+	return &opts
+}
+
+func (g *Graph) tableKey(n *Node, opts *TableOptions) string {
+	if opts.Key == KeyByLabel {
+		return g.label(n)
+	}
+
+	return strconv.FormatUint(uint64(n.ID), 10)
+}
+
+// tableResolver finds, or creates, the nodes referenced by an edge list or a matrix.
+type tableResolver struct {
+	g     *Graph
+	opts  *TableOptions
+	nodes map[string]*Node
+}
+
+func newTableResolver(g *Graph, opts *TableOptions) *tableResolver {
+	r := &tableResolver{
+		g:     g,
+		opts:  opts,
+		nodes: make(map[string]*Node),
+	}
+
+	for _, n := range g.Nodes {
+		r.nodes[g.tableKey(n, opts)] = n
+	}
+
+	return r
+}
+
+// resolve returns the node for a key.
+// Nodes missing from the graph are created. Unless given by NodeData, the data of the
+// new node is the uint32 id read when keyed by id, otherwise the label. The graph assigns
+// the Node.ID of new nodes as usual, so ids read only identify nodes within the input.
+func (r *tableResolver) resolve(key string) (*Node, error) {
+	if n, hasNode := r.nodes[key]; hasNode {
+		return n, nil
+	}
+
+	var n *Node
+	if r.opts.NodeData != nil {
+		n = r.g.NewNode(r.opts.NodeData(key))
+	} else if r.opts.Key == KeyByLabel {
+		n = r.g.NewNode(key)
+	} else {
+		id, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node id %q: %v", key, err)
+		}
+		n = r.g.NewNode(uint32(id))
+	}
+
+	r.nodes[key] = n
+	return n, nil
+}
+
+// WriteEdgeList writes every edge of the graph as a source, destination and optional weight record.
+func (g *Graph) WriteEdgeList(w io.Writer, opts *TableOptions) error {
+	opts = opts.withDefaults()
+	cw := csv.NewWriter(w)
+	cw.Comma = opts.Comma
+
+	if opts.Header {
+		header := []string{"source", "destination"}
+		if opts.Weights {
+			header = append(header, "weight")
+		}
+		cw.Write(header)
+	}
+
+	for _, n := range g.sortedNodes() {
+		for _, e := range n.Edges {
+			if e.Source != n {
+				continue
+			}
+
+			record := []string{g.tableKey(n, opts), g.tableKey(e.Destination, opts)}
+			if opts.Weights {
+				weight := ""
+				if e.Data != nil {
+					weight = opts.FormatWeight(e.Data)
+				}
+				record = append(record, weight)
+			}
+			cw.Write(record)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadEdgeList reads an edge list into the graph.
+// Each record creates an edge from the first to the second node.
+func (g *Graph) ReadEdgeList(r io.Reader, opts *TableOptions) error {
+	opts = opts.withDefaults()
+	cr := csv.NewReader(r)
+	cr.Comma = opts.Comma
+	cr.FieldsPerRecord = -1
+	resolver := newTableResolver(g, opts)
+
+	line := 0
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line++
+		if line == 1 && opts.Header {
+			continue
+		}
+
+		if len(record) < 2 {
+			return fmt.Errorf("line %v: expected at least two fields, got %v", line, len(record))
+		}
+
+		source, err := resolver.resolve(record[0])
+		if err != nil {
+			return fmt.Errorf("line %v: %v", line, err)
+		}
+		destination, err := resolver.resolve(record[1])
+		if err != nil {
+			return fmt.Errorf("line %v: %v", line, err)
+		}
+
+		edge := source.DependOn(destination)
+		if edge != nil && opts.Weights && len(record) > 2 && record[2] != "" {
+			edge.Data, err = opts.ParseWeight(record[2])
+			if err != nil {
+				return fmt.Errorf("line %v: invalid weight %q: %v", line, record[2], err)
+			}
+		}
+	}
+}
+
+// WriteAdjacencyMatrix writes the graph as an adjacency matrix.
+// The first row and column hold the node keys. A cell is 1, or the weight, if
+// the row node depends on the column node, and 0 otherwise.
+func (g *Graph) WriteAdjacencyMatrix(w io.Writer, opts *TableOptions) error {
+	opts = opts.withDefaults()
+	cw := csv.NewWriter(w)
+	cw.Comma = opts.Comma
+
+	nodes := g.sortedNodes()
+	index := make(map[*Node]int, len(nodes))
+	header := make([]string, len(nodes)+1)
+	for i, n := range nodes {
+		index[n] = i
+		header[i+1] = g.tableKey(n, opts)
+	}
+	cw.Write(header)
+
+	for _, n := range nodes {
+		row := make([]string, len(nodes)+1)
+		row[0] = g.tableKey(n, opts)
+		for i := range nodes {
+			row[i+1] = "0"
+		}
+
+		for _, e := range n.Edges {
+			if e.Source != n {
+				continue
+			}
+
+			cell := "1"
+			if opts.Weights && e.Data != nil {
+				cell = opts.FormatWeight(e.Data)
+			}
+			row[index[e.Destination]+1] = cell
+		}
+		cw.Write(row)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadAdjacencyMatrix reads an adjacency matrix into the graph.
+// Empty and 0 cells mean no edge.
+func (g *Graph) ReadAdjacencyMatrix(r io.Reader, opts *TableOptions) error {
+	opts = opts.withDefaults()
+	cr := csv.NewReader(r)
+	cr.Comma = opts.Comma
+	resolver := newTableResolver(g, opts)
+
+	header, err := cr.Read()
+	if err != nil {
+		return err
+	}
+
+	columns := make([]*Node, len(header)-1)
+	for i, key := range header[1:] {
+		if columns[i], err = resolver.resolve(key); err != nil {
+			return fmt.Errorf("header: %v", err)
+		}
+	}
+
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		source, err := resolver.resolve(row[0])
+		if err != nil {
+			return fmt.Errorf("line %v: %v", line, err)
+		}
+
+		for i, cell := range row[1:] {
+			if cell == "" || cell == "0" {
+				continue
+			}
+
+			edge := source.DependOn(columns[i])
+			if edge != nil && opts.Weights {
+				edge.Data, err = opts.ParseWeight(cell)
+				if err != nil {
+					return fmt.Errorf("line %v: invalid weight %q: %v", line, cell, err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package graph
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGraph_edgeList(t *testing.T) {
+	g := NewGraph()
+	g.NodeStringer = func(data interface{}) string {
+		return data.(string)
+	}
+
+	a := g.NewNode("a")
+	b := g.NewNode("b")
+	c := g.NewNode("c")
+	a.DependOn(b).Data = 2.5
+	b.DependOn(c)
+
+	var buf bytes.Buffer
+	opts := &TableOptions{Comma: '\t', Key: KeyByLabel, Weights: true}
+	if err := g.WriteEdgeList(&buf, opts); err != nil {
+		t.Fatalf("Unable to write edge list: %v", err)
+	}
+
+	expected := "a\tb\t2.5\nb\tc\t\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	g2 := NewGraph()
+	if err := g2.ReadEdgeList(&buf, opts); err != nil {
+		t.Fatalf("Unable to read edge list: %v", err)
+	}
+
+	e := g2.Find("a").DependsOnAdjacent(g2.Find("b"))
+	if e == nil || e.Data != 2.5 {
+		t.Errorf("The weighted edge was not read")
+	}
+
+	if g2.Find("b").DependsOnAdjacent(g2.Find("c")) == nil {
+		t.Errorf("The unweighted edge was not read")
+	}
+}
+
+func TestGraph_adjacencyMatrix(t *testing.T) {
+	g := NewGraph()
+	if err := g.ReadAdjacencyMatrix(strings.NewReader(",0,1,2\n0,0,1,1\n1,0,0,1\n2,0,0,0\n"), nil); err != nil {
+		t.Fatalf("Unable to read matrix: %v", err)
+	}
+
+	if g.Size() != 3 || g.NumberOfEdges() != 3 {
+		t.Fatalf("Expected 3 nodes and 3 edges, got %v and %v", g.Size(), g.NumberOfEdges())
+	}
+
+	var buf bytes.Buffer
+	if err := g.WriteAdjacencyMatrix(&buf, nil); err != nil {
+		t.Fatalf("Unable to write matrix: %v", err)
+	}
+
+	expected := ",0,1,2\n0,0,1,1\n1,0,0,1\n2,0,0,0\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestGraph_edgeListIDs(t *testing.T) {
+	g := NewGraph()
+	if err := g.ReadEdgeList(strings.NewReader("0,5\n"), nil); err != nil {
+		t.Fatalf("Unable to read edge list: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		g.NewNode(fmt.Sprint(i))
+	}
+
+	ids := make(map[uint32]bool)
+	for _, n := range g.Nodes {
+		if ids[n.ID] {
+			t.Fatalf("Duplicate id %v", n.ID)
+		}
+		ids[n.ID] = true
+	}
+
+	var buf bytes.Buffer
+	g.WriteBinary(&buf, nil)
+	read, err := ReadBinary(&buf, nil)
+	if err != nil {
+		t.Fatalf("Unable to read graph: %v", err)
+	}
+
+	if read.Find(uint32(0)).DependsOnAdjacent(read.Find(uint32(5))) == nil {
+		t.Errorf("Expected the edge from 0 to 5 to survive the round trip")
+	}
+}
+
+func TestGraph_edgeListKeyFunc(t *testing.T) {
+	type record struct {
+		name string
+	}
+
+	g := NewGraph()
+	g.KeyFunc = func(data interface{}) interface{} {
+		return data.(*record).name
+	}
+	g.NodeStringer = func(data interface{}) string {
+		return data.(*record).name
+	}
+
+	opts := &TableOptions{
+		Key: KeyByLabel,
+		NodeData: func(key string) interface{} {
+			return &record{key}
+		},
+	}
+	if err := g.ReadEdgeList(strings.NewReader("a,b\nb,c\n"), opts); err != nil {
+		t.Fatalf("Unable to read edge list: %v", err)
+	}
+
+	if g.Size() != 3 || g.Find(&record{"a"}).DependsOnAdjacent(g.Find(&record{"b"})) == nil {
+		t.Errorf("Expected the records to be created through NodeData")
+	}
+}
@@ -0,0 +1,403 @@
+package graph
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// FrozenGraph is an immutable compressed sparse row view of a graph for fast analytics.
+// The nodes are numbered densely in the order of their ids, and the edges of every node
+// are stored contiguously, so the algorithms work on integers instead of chasing pointers.
+// Changes to the graph after freezing are not reflected.
+// As on the graph, the topological sort and the strongly connected components ignore soft edges,
+// while the traversals and shortest paths follow edges of every kind.
+type FrozenGraph struct {
+	nodes []*Node
+	index map[*Node]int
+
+	// The dependencies of node i are out[outStart[i]:outStart[i+1]]
+	outStart []int
+	out      []int32
+	outKind  []EdgeKind
+	outEdge  []*Edge
+
+	// The dependents of node i are in[inStart[i]:inStart[i+1]]
+	inStart []int
+	in      []int32
+	inKind  []EdgeKind
+	inEdge  []*Edge
+
+	undirected bool
+}
+
+// Freeze returns a read-only compressed sparse row view of the graph.
+// In undirected mode, every edge is stored in both directions.
+func (g *Graph) Freeze() *FrozenGraph {
+	nodes := g.sortedNodes()
+	f := &FrozenGraph{
+		nodes:      nodes,
+		index:      make(map[*Node]int, len(nodes)),
+		outStart:   make([]int, len(nodes)+1),
+		inStart:    make([]int, len(nodes)+1),
+		undirected: g.Undirected,
+	}
+	for i, n := range nodes {
+		f.index[n] = i
+	}
+
+	// Count the degrees, then fill the rows
+	forEach := func(add func(from, to int, e *Edge)) {
+		for i, n := range nodes {
+			for _, e := range n.Edges {
+				if e.Source != n {
+					continue
+				}
+
+				to := f.index[e.Destination]
+				add(i, to, e)
+				if g.Undirected && to != i {
+					add(to, i, e)
+				}
+			}
+		}
+	}
+
+	forEach(func(from, to int, e *Edge) {
+		f.outStart[from+1]++
+		f.inStart[to+1]++
+	})
+	for i := 0; i < len(nodes); i++ {
+		f.outStart[i+1] += f.outStart[i]
+		f.inStart[i+1] += f.inStart[i]
+	}
+
+	edges := f.outStart[len(nodes)]
+	f.out, f.outKind, f.outEdge = make([]int32, edges), make([]EdgeKind, edges), make([]*Edge, edges)
+	f.in, f.inKind, f.inEdge = make([]int32, edges), make([]EdgeKind, edges), make([]*Edge, edges)
+
+	outNext := append([]int(nil), f.outStart[:len(nodes)]...)
+	inNext := append([]int(nil), f.inStart[:len(nodes)]...)
+	forEach(func(from, to int, e *Edge) {
+		o := outNext[from]
+		f.out[o], f.outKind[o], f.outEdge[o] = int32(to), e.Kind, e
+		outNext[from]++
+
+		i := inNext[to]
+		f.in[i], f.inKind[i], f.inEdge[i] = int32(from), e.Kind, e
+		inNext[to]++
+	})
+
+	return f
+}
+
+// Len returns the number of nodes
+func (f *FrozenGraph) Len() int {
+	return len(f.nodes)
+}
+
+// Node returns the node with the given index
+func (f *FrozenGraph) Node(i int) *Node {
+	return f.nodes[i]
+}
+
+// Index returns the index of the node, or -1 if the node was not in the graph when frozen
+func (f *FrozenGraph) Index(n *Node) int {
+	i, hasNode := f.index[n]
+	if !hasNode {
+		return -1
+	}
+
+	return i
+}
+
+// Nodes returns the nodes with the given indexes
+func (f *FrozenGraph) Nodes(indexes []int) []*Node {
+	nodes := make([]*Node, len(indexes))
+	for k, i := range indexes {
+		nodes[k] = f.nodes[i]
+	}
+
+	return nodes
+}
+
+// Neighbors returns the indexes of the nodes adjacent to node i in the given direction.
+// The returned slice must not be modified.
+func (f *FrozenGraph) Neighbors(i int, direction Direction) []int32 {
+	if direction == Inbound {
+		return f.in[f.inStart[i]:f.inStart[i+1]]
+	}
+
+	return f.out[f.outStart[i]:f.outStart[i+1]]
+}
+
+// BreadthFirst visits the nodes reachable from start in the given direction in breadth first order,
+// start included, until visit returns false.
+func (f *FrozenGraph) BreadthFirst(start int, direction Direction, visit func(i int) bool) {
+	visited := make([]bool, len(f.nodes))
+	visited[start] = true
+	queue := []int{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if !visit(current) {
+			return
+		}
+
+		for _, next := range f.Neighbors(current, direction) {
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, int(next))
+			}
+		}
+	}
+}
+
+// DepthFirst visits the nodes reachable from start in the given direction in depth first preorder,
+// start included, until visit returns false.
+func (f *FrozenGraph) DepthFirst(start int, direction Direction, visit func(i int) bool) {
+	visited := make([]bool, len(f.nodes))
+	stack := []int{start}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[current] {
+			continue
+		}
+
+		visited[current] = true
+		if !visit(current) {
+			return
+		}
+
+		// Push in reverse, such that the neighbors are visited in order
+		neighbors := f.Neighbors(current, direction)
+		for k := len(neighbors) - 1; k >= 0; k-- {
+			if !visited[neighbors[k]] {
+				stack = append(stack, int(neighbors[k]))
+			}
+		}
+	}
+}
+
+// TopologicalSort returns the node indexes such that dependencies come before their dependents,
+// using Kahn's algorithm. Soft edges are ignored.
+func (f *FrozenGraph) TopologicalSort() ([]int, error) {
+	if f.undirected {
+		return nil, fmt.Errorf("an undirected graph cannot be sorted topologically")
+	}
+
+	// The number of dependencies not yet sorted
+	pending := make([]int, len(f.nodes))
+	var queue []int
+	for i := range f.nodes {
+		for o := f.outStart[i]; o < f.outStart[i+1]; o++ {
+			if f.outKind[o].Orders() {
+				pending[i]++
+			}
+		}
+
+		if pending[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+
+	sorted := make([]int, 0, len(f.nodes))
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		sorted = append(sorted, current)
+
+		for k := f.inStart[current]; k < f.inStart[current+1]; k++ {
+			if !f.inKind[k].Orders() {
+				continue
+			}
+
+			dependent := f.in[k]
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				queue = append(queue, int(dependent))
+			}
+		}
+	}
+
+	if len(sorted) < len(f.nodes) {
+		return nil, fmt.Errorf("Not a DAG")
+	}
+
+	return sorted, nil
+}
+
+// StronglyConnectedComponents returns the strongly connected components as node indexes,
+// such that dependencies come before their dependents. Soft edges are ignored.
+func (f *FrozenGraph) StronglyConnectedComponents() (components [][]int) {
+	size := len(f.nodes)
+	index := make([]int, size)
+	lowlink := make([]int, size)
+	onStack := make([]bool, size)
+	for i := range index {
+		index[i] = -1
+	}
+
+	var stack []int
+	counter := 0
+	visit := func(i int) {
+		index[i] = counter
+		lowlink[i] = counter
+		counter++
+		stack = append(stack, i)
+		onStack[i] = true
+	}
+
+	type frame struct {
+		node, edge int
+	}
+
+	for root := 0; root < size; root++ {
+		if index[root] >= 0 {
+			continue
+		}
+
+		visit(root)
+		calls := []frame{{root, f.outStart[root]}}
+		for len(calls) > 0 {
+			c := &calls[len(calls)-1]
+			n := c.node
+
+			if c.edge < f.outStart[n+1] {
+				o := c.edge
+				c.edge++
+				if !f.outKind[o].Orders() {
+					continue
+				}
+
+				m := int(f.out[o])
+				if index[m] < 0 {
+					visit(m)
+					calls = append(calls, frame{m, f.outStart[m]})
+				} else if onStack[m] && index[m] < lowlink[n] {
+					lowlink[n] = index[m]
+				}
+				continue
+			}
+
+			calls = calls[:len(calls)-1]
+			if len(calls) > 0 {
+				parent := calls[len(calls)-1].node
+				if lowlink[n] < lowlink[parent] {
+					lowlink[parent] = lowlink[n]
+				}
+			}
+
+			if lowlink[n] != index[n] {
+				continue
+			}
+
+			var component []int
+			for {
+				m := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[m] = false
+				component = append(component, m)
+				if m == n {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	return
+}
+
+// ShortestPath returns the node indexes on a shortest path of outbound edges from one node to another,
+// both included, and its length. If weight is nil, every edge counts as one and a breadth first
+// search is used, otherwise Dijkstra's algorithm. If there is no path, nil and -1 are returned.
+// Edges of every kind are followed, use a weight of +Inf to exclude edges.
+func (f *FrozenGraph) ShortestPath(from, to int, weight func(*Edge) float64) ([]int, float64, error) {
+	size := len(f.nodes)
+	parent := make([]int, size)
+	distance := make([]float64, size)
+	for i := range parent {
+		parent[i] = -1
+		distance[i] = math.Inf(1)
+	}
+	distance[from] = 0
+
+	if weight == nil {
+		queue := []int{from}
+		for len(queue) > 0 && math.IsInf(distance[to], 1) {
+			current := queue[0]
+			queue = queue[1:]
+			for _, next := range f.Neighbors(current, Outbound) {
+				if math.IsInf(distance[next], 1) {
+					distance[next] = distance[current] + 1
+					parent[next] = current
+					queue = append(queue, int(next))
+				}
+			}
+		}
+	} else {
+		done := make([]bool, size)
+		queue := &frozenQueue{{from, 0}}
+		for queue.Len() > 0 {
+			current := heap.Pop(queue).(frozenItem).node
+			if done[current] {
+				continue
+			}
+			done[current] = true
+			if current == to {
+				break
+			}
+
+			for o := f.outStart[current]; o < f.outStart[current+1]; o++ {
+				w := weight(f.outEdge[o])
+				if w < 0 {
+					return nil, -1, fmt.Errorf("edge %v has negative weight %v", f.outEdge[o], w)
+				}
+
+				next := int(f.out[o])
+				if d := distance[current] + w; d < distance[next] {
+					distance[next] = d
+					parent[next] = current
+					heap.Push(queue, frozenItem{next, d})
+				}
+			}
+		}
+	}
+
+	if math.IsInf(distance[to], 1) {
+		return nil, -1, nil
+	}
+
+	var path []int
+	for i := to; i >= 0; i = parent[i] {
+		path = append(path, i)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path, distance[to], nil
+}
+
+// frozenItem is a node and its tentative distance in the queue of Dijkstra's algorithm
+type frozenItem struct {
+	node     int
+	distance float64
+}
+
+// frozenQueue is a min-heap of frozen items
+type frozenQueue []frozenItem
+
+func (q frozenQueue) Len() int            { return len(q) }
+func (q frozenQueue) Less(i, j int) bool  { return q[i].distance < q[j].distance }
+func (q frozenQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *frozenQueue) Push(x interface{}) { *q = append(*q, x.(frozenItem)) }
+
+func (q *frozenQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,247 @@
+package graph
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func newRandomGraph(seed int64, size, edges int) *Graph {
+	rnd := rand.New(rand.NewSource(seed))
+	g := NewGraph()
+	for i := 0; i < size; i++ {
+		g.NewNode(i)
+	}
+
+	for i := 0; i < edges; i++ {
+		kind := HardEdge
+		if rnd.Intn(5) == 0 {
+			kind = SoftEdge
+		}
+		g.Find(rnd.Intn(size)).DependOnKind(g.Find(rnd.Intn(size)), kind)
+	}
+
+	return g
+}
+
+func TestFrozenGraph(t *testing.T) {
+	g := NewGraph()
+	n := make([]*Node, 5)
+	for i := range n {
+		n[i] = g.NewNode(i)
+	}
+	n[0].DependOn(n[1])
+	n[0].DependOn(n[2])
+	n[1].DependOn(n[3])
+	n[2].DependOnKind(n[3], SoftEdge)
+
+	f := g.Freeze()
+	if f.Len() != 5 || f.Index(n[3]) != 3 || f.Node(2) != n[2] || f.Index(NewGraph().NewNode(0)) != -1 {
+		t.Fatalf("Expected the nodes to be indexed by id")
+	}
+
+	if out, in := f.Neighbors(0, Outbound), f.Neighbors(3, Inbound); len(out) != 2 || out[0] != 1 || len(in) != 2 {
+		t.Errorf("Unexpected neighbors %v and %v", out, in)
+	}
+
+	var bfs, dfs []int
+	f.BreadthFirst(0, Outbound, func(i int) bool {
+		bfs = append(bfs, i)
+		return true
+	})
+	f.DepthFirst(0, Outbound, func(i int) bool {
+		dfs = append(dfs, i)
+		return true
+	})
+	if len(bfs) != 4 || bfs[3] != 3 || len(dfs) != 4 || dfs[2] != 3 {
+		t.Errorf("Unexpected traversals %v and %v", bfs, dfs)
+	}
+
+	sorted, err := f.TopologicalSort()
+	if err != nil || len(sorted) != 5 || sorted[len(sorted)-1] != 0 {
+		t.Errorf("Expected 0 last, got %v, %v", sorted, err)
+	}
+
+	path, length, err := f.ShortestPath(0, 3, nil)
+	if err != nil || length != 2 || len(path) != 3 || path[1] != 1 {
+		t.Errorf("Expected the path 0, 1, 3, got %v of length %v", path, length)
+	}
+
+	path, length, _ = f.ShortestPath(0, 3, func(e *Edge) float64 {
+		if e.Source == n[0] && e.Destination == n[1] {
+			return 5
+		}
+		return 1
+	})
+	if length != 2 || len(path) != 3 || path[1] != 2 {
+		t.Errorf("Expected the path 0, 2, 3, got %v of length %v", path, length)
+	}
+
+	if path, length, _ := f.ShortestPath(3, 0, nil); path != nil || length != -1 {
+		t.Errorf("Expected no path, got %v", path)
+	}
+
+	if _, _, err := f.ShortestPath(0, 3, func(*Edge) float64 { return -1 }); err == nil {
+		t.Errorf("Expected an error for negative weights")
+	}
+
+	// The frozen graph does not change with the graph
+	n[3].DependOn(n[0])
+	if _, err := f.TopologicalSort(); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if _, err := g.Freeze().TopologicalSort(); err == nil {
+		t.Errorf("Expected an error for a cyclic graph")
+	}
+}
+
+func TestFrozenGraph_random(t *testing.T) {
+	g := newRandomGraph(1, 200, 300)
+	f := g.Freeze()
+
+	components := g.StronglyConnectedComponents()
+	frozen := f.StronglyConnectedComponents()
+	if len(components) != len(frozen) {
+		t.Fatalf("Expected %v components, got %v", len(components), len(frozen))
+	}
+	for i := range components {
+		nodes := f.Nodes(frozen[i])
+		if len(nodes) != len(components[i]) {
+			t.Fatalf("Component %v differs: %v and %v", i, components[i], nodes)
+		}
+		for k := range nodes {
+			if nodes[k] != components[i][k] {
+				t.Fatalf("Component %v differs: %v and %v", i, components[i], nodes)
+			}
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		path, length, _ := f.ShortestPath(i, 199-i, nil)
+		_, weighted, _ := f.ShortestPath(i, 199-i, func(*Edge) float64 { return 1 })
+		if length != weighted {
+			t.Errorf("Expected the same length from %v, got %v and %v", i, length, weighted)
+		}
+
+		for k := 1; k < len(path); k++ {
+			if f.Node(path[k-1]).DependsOnAdjacent(f.Node(path[k])) == nil {
+				t.Errorf("%v is not a path", path)
+			}
+		}
+	}
+
+	dag := newRandomGraph(2, 200, 0)
+	for i := 0; i < 500; i++ {
+		a, b := (i*7)%200, (i*13)%200
+		if a > b {
+			dag.Find(a).DependOn(dag.Find(b))
+		}
+	}
+
+	sorted, err := dag.Freeze().TopologicalSort()
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	position := make(map[*Node]int)
+	for k, i := range sorted {
+		position[dag.Find(i)] = k
+	}
+	for _, n := range dag.Nodes {
+		for _, e := range n.Edges {
+			if e.Source == n && position[e.Destination] >= position[n] {
+				t.Fatalf("%v depends on %v, which comes later", n, e.Destination)
+			}
+		}
+	}
+}
+
+func TestFrozenGraph_undirected(t *testing.T) {
+	g := NewGraph()
+	g.Undirected = true
+	g.NewNode(0).Connect(g.NewNode(1))
+
+	f := g.Freeze()
+	if len(f.Neighbors(1, Outbound)) != 1 || len(f.Neighbors(0, Inbound)) != 1 {
+		t.Errorf("Expected the edge in both directions")
+	}
+
+	if _, err := f.TopologicalSort(); err == nil {
+		t.Errorf("Expected an error for an undirected graph")
+	}
+}
+
+func newRandomDAG(seed int64, size, edges int) *Graph {
+	rnd := rand.New(rand.NewSource(seed))
+	g := newRandomGraph(seed, size, 0)
+	for i := 0; i < edges; i++ {
+		a, b := rnd.Intn(size), rnd.Intn(size)
+		if a > b {
+			g.Find(a).DependOn(g.Find(b))
+		}
+	}
+
+	return g
+}
+
+func BenchmarkGraph_StronglyConnectedComponents(b *testing.B) {
+	g := newRandomGraph(1, 10000, 30000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.StronglyConnectedComponents()
+	}
+}
+
+func BenchmarkFrozenGraph_StronglyConnectedComponents(b *testing.B) {
+	f := newRandomGraph(1, 10000, 30000).Freeze()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.StronglyConnectedComponents()
+	}
+}
+
+func BenchmarkGraph_TopologicalSort(b *testing.B) {
+	g := newRandomDAG(1, 10000, 60000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.TopologicalSort(nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFrozenGraph_TopologicalSort(b *testing.B) {
+	f := newRandomDAG(1, 10000, 60000).Freeze()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.TopologicalSort(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGraph_BreadthFirst(b *testing.B) {
+	g := newRandomGraph(1, 10000, 30000)
+	start := g.Find(0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		visited := map[*Node]bool{start: true}
+		queue := []*Node{start}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, e := range current.Edges {
+				if e.Source == current && !visited[e.Destination] {
+					visited[e.Destination] = true
+					queue = append(queue, e.Destination)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkFrozenGraph_BreadthFirst(b *testing.B) {
+	f := newRandomGraph(1, 10000, 30000).Freeze()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.BreadthFirst(0, Outbound, func(int) bool { return true })
+	}
+}
@@ -2,14 +2,20 @@ package graph
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"sort"
 )
 
 // Graph represents a graph
 type Graph struct {
-	// Nodes contains all the nodes for the graph
+	// Nodes contains all the nodes for the graph, keyed by the key of their data
 	Nodes map[interface{}]*Node
 
+	// KeyFunc returns the key identifying the node with the given data, see key.
+	// It must be set before adding nodes.
+	KeyFunc func(interface{}) interface{}
+
 	// NodeStringer is a function for stringifying nodes
 	NodeStringer func(interface{}) string
 
@@ -19,35 +25,68 @@ type Graph struct {
 	// OnEdgeCreated is called when an edge is created
 	OnEdgeCreated func(*Edge)
 
-	// OnSameNodeEdge is called when an edge is created that has same source and destination
+	// OnSameNodeEdge is called when an edge is created that has same source and destination,
+	// unless self-loops are allowed
 	OnSameNodeEdge func(*Node)
 
 	// OnDuplicateEdge is called when trying to create an edge that already exists
 	OnDuplicateEdge func(*Edge)
 
+	// Multigraph allows parallel edges between two nodes, as long as their labels differ
+	Multigraph bool
+
+	// Undirected makes edges symmetric, such that an edge connects two nodes in both directions
+	Undirected bool
+
+	// AllowSelfLoops allows edges from a node to itself.
+	// A self-loop is a trivial cycle, so graphs with self-loops cannot be sorted topologically.
+	AllowSelfLoops bool
+
 	// Regions is used to group nodes into regions
 	Regions map[interface{}][]*Node
+
+	// RegionParents maps a region to its parent region, see SetRegionParent
+	RegionParents map[interface{}]interface{}
+
+	// OnRegionChanged is called when a node is moved from one region to another.
+	// The nil region means no region.
+	OnRegionChanged func(node *Node, from, to interface{})
+
+	// Rules restricts the dependencies between regions, see RuleSet
+	Rules *RuleSet
+
+	// OnRuleViolation is called when an edge violating the rules is created
+	OnRuleViolation func(Violation)
+
+	// acyclic refuses edges closing a cycle, see EnableAcyclic
+	acyclic bool
+
+	// order is the maintained topological order, nil if not kept
+	order []*Node
+
+	// edges indexes the edges by their source and destination
+	edges map[edgeKey][]*Edge
+
+	// regionEdges counts the ordering edges from one region to another
+	regionEdges map[interface{}]map[interface{}]int
+
+	// attrIndexes indexes the nodes by attribute name and value, see IndexNodeAttr
+	attrIndexes map[string]map[interface{}][]*Node
 }
 
 // NewGraph returns a new graph
 func NewGraph() *Graph {
 	return &Graph{
-		Nodes:   make(map[interface{}]*Node),
-		Regions: make(map[interface{}][]*Node),
+		Nodes:         make(map[interface{}]*Node),
+		Regions:       make(map[interface{}][]*Node),
+		RegionParents: make(map[interface{}]interface{}),
+		edges:         make(map[edgeKey][]*Edge),
 	}
 }
 
-// Stringify stringifies the graph
+// Stringify prints the edges of the graph to stdout
 func (g *Graph) Stringify() {
-	for _, n := range g.Nodes {
-		for _, e := range n.Edges {
-			if e.Source != n {
-				continue
-			}
-
-			fmt.Printf("[%v] %v -(%v)> [%v] %v\n", n, g.NodeStringer(n.Data), e.Data, e.Destination, g.NodeStringer(e.Destination.Data))
-		}
-	}
+	g.Fprint(os.Stdout, EdgeFormatter)
 }
 
 func (g *Graph) NumberOfEdges() int {
@@ -65,65 +104,28 @@ func (g *Graph) NumberOfEdges() int {
 	return c
 }
 
+// PrintNodes prints the nodes of the graph to stdout
 func (g *Graph) PrintNodes() {
-	for _, node := range g.Nodes {
-		if node.Data != nil {
-			fmt.Printf("[%v]: %v(%T)\n", node, g.NodeStringer(node.Data), node.Data)
-		} else {
-			fmt.Printf("[%v]: NIL(%T)\n", node, node.Data)
-		}
-	}
+	g.Fprint(os.Stdout, NodeFormatter)
 }
 
+// Print prints the nodes of the graph and their edges to stdout
 func (g *Graph) Print() {
-	edges := make(map[*Edge]struct{ id, num int })
-	id := 0
-	edgeCounter := 0
-
-	ids := make([]int, len(g.Nodes))
-	i := 0
-	for _, n := range g.Nodes {
-		ids[i] = int(n.ID)
-		i++
-	}
-
-	sort.Ints(ids)
-
-	//for _, node := range g.Nodes {
-	for _, i := range ids {
-		node := g.FindById(uint32(i))
-		if node.Data != nil {
-			if g.NodeStringer != nil {
-				fmt.Printf("[%v]: %v(%T)\n", node, g.NodeStringer(node.Data), node.Data)
-			} else {
-				fmt.Printf("[%v]: %v(%T)\n", node, node.Data, node.Data)
-			}
-		} else {
-			fmt.Printf("[%v]: NIL(%T)\n", node, node.Data)
-		}
+	g.Fprint(os.Stdout, DetailFormatter)
+}
 
-		for i, edge := range node.Edges {
-			edgeCounter++
-			value, hasEdge := edges[edge]
-			if !hasEdge {
-				value = struct{ id, num int }{id, 1}
-				edges[edge] = value
-				id++
-			} else {
-				value.num++
-				edges[edge] = value
-			}
+// Fprint writes the graph to w using the given formatter
+func (g *Graph) Fprint(w io.Writer, f Formatter) error {
+	return f.Format(w, g)
+}
 
-			inbound := edge.Destination == node
-			if inbound {
-				fmt.Printf("  [%v] %v <- %v (%p) [%v/%v]\n", i, edge.Destination.ID, edge.Source.ID, edge, value.id, value.num)
-			} else {
-				fmt.Printf("  [%v] %v -> %v (%p) [%v/%v]\n", i, edge.Source.ID, edge.Destination.ID, edge, value.id, value.num)
-			}
-		}
+// label returns the label of a node, using the NodeStringer if set.
+func (g *Graph) label(n *Node) string {
+	if g.NodeStringer != nil {
+		return g.NodeStringer(n.Data)
 	}
 
-	fmt.Printf("Nodes: %v, Unique edges: %v, total edges: %v\n", len(g.Nodes), len(edges), edgeCounter)
+	return fmt.Sprint(n.Data)
 }
 
 // NewNode will add a new node to the graph.
@@ -131,10 +133,7 @@ func (g *Graph) Print() {
 func (g *Graph) NewNode(data interface{}) (node *Node) {
 	node = g.Find(data)
 	if node == nil {
-		node = newNode(data)
-		node.ID = uint32(len(g.Nodes))
-		node.graph = g
-		g.Nodes[data] = node
+		node = g.createNode(data)
 		if g.OnNodeCreated != nil {
 			g.OnNodeCreated(node)
 		}
@@ -143,18 +142,39 @@ func (g *Graph) NewNode(data interface{}) (node *Node) {
 	return
 }
 
+// createNode adds a node for data not in the graph
+func (g *Graph) createNode(data interface{}) *Node {
+	node := newNode(data)
+	node.ID = uint32(len(g.Nodes))
+	node.graph = g
+	g.Nodes[g.key(data)] = node
+	g.appendOrder(node)
+
+	return node
+}
+
 func (g *Graph) addNode(node *Node) {
 	if node == nil {
 		return
 	}
 
-	g.Nodes[node.Data] = node
+	g.Nodes[g.key(node.Data)] = node
+}
+
+// key returns the key of the node with the given data.
+// Without a KeyFunc, the data itself is the key and must be comparable.
+func (g *Graph) key(data interface{}) interface{} {
+	if g.KeyFunc == nil {
+		return data
+	}
+
+	return g.KeyFunc(data)
 }
 
 // Find will find a graph node in the graph, given the ast node.
 // If the ast node is not in the graph, nil is returned.
 func (g *Graph) Find(data interface{}) *Node {
-	node, hasNode := g.Nodes[data]
+	node, hasNode := g.Nodes[g.key(data)]
 	if hasNode {
 		return node
 	} else {
@@ -173,6 +193,20 @@ func (g *Graph) FindById(i uint32) *Node {
 	return nil
 }
 
+// sortedNodes returns the nodes of the graph ordered by their id.
+func (g *Graph) sortedNodes() []*Node {
+	nodes := make([]*Node, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		nodes = append(nodes, n)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	return nodes
+}
+
 // Size returns the number of nodes in the graph.
 func (g *Graph) Size() int {
 	return len(g.Nodes)
@@ -190,7 +224,18 @@ func (g *Graph) SizeEdges() int {
 }
 
 // HasCyclicDependencies returns true if the graph has cyclic dependencies.
+// Soft edges are ignored. In undirected mode, any cycle regardless of direction counts.
 func (g *Graph) HasCyclicDependencies() bool {
+	if g.Undirected {
+		_, cyclic := g.undirectedComponents()
+		for _, c := range cyclic {
+			if c {
+				return true
+			}
+		}
+		return false
+	}
+
 	for _, n := range g.Nodes {
 		deps := []*Node{}
 		if n.hasCyclicDependency(deps) {
@@ -528,6 +528,79 @@ func TestGraph_topologicalSortRegions(t *testing.T) {
 	}
 }
 
+func TestGraph_topologicalSortAcrossRegions(t *testing.T) {
+	g := NewGraph()
+	n1 := g.NewNode(1).PutIntoRegion(1)
+	n2 := g.NewNode(2).PutIntoRegion(2)
+	n3 := g.NewNode(3).PutIntoRegion(2)
+	n1.DependOn(n2)
+	n2.DependOn(n3)
+
+	// Edges between regions order the nodes
+	sorted, err := NewTopologicalSort().Sort([]*Node{n1, n2, n3})
+	if err != nil || len(sorted) != 3 || sorted[0] != n3 || sorted[2] != n1 {
+		t.Errorf("Expected 3, 2, 1, got %v, %v", sorted, err)
+	}
+
+	// A cycle across regions fails the sort, unless the regions are sorted separately
+	n3.DependOn(n1)
+	if _, err := NewTopologicalSort().Sort([]*Node{n1, n2, n3}); err == nil {
+		t.Errorf("Expected an error for a cycle across regions")
+	}
+	if _, err := NewRegionTopologicalSort().Sort([]*Node{n1, n2, n3}); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestGraph_edgeKinds(t *testing.T) {
+	g := NewGraph()
+
+	n1 := g.NewNode(1)
+	n2 := g.NewNode(2)
+	n3 := g.NewNode(3)
+	n4 := g.NewNode(4)
+
+	n1.DependOn(n2)
+	n2.DependOnKind(n3, OrderOnlyEdge)
+	n3.DependOnKind(n1, SoftEdge)
+	n4.DependOnKind(n2, SoftEdge)
+
+	if g.HasCyclicDependencies() {
+		t.Errorf("Soft edges should be ignored by cycle detection")
+	}
+
+	sorted, err := g.TopologicalSort(nil)
+	if err != nil {
+		t.Fatalf("Unable to sort topological: %v", err)
+	}
+
+	if findNode(sorted, n3) > findNode(sorted, n2) || findNode(sorted, n2) > findNode(sorted, n1) {
+		t.Errorf("Unexpected order %v", sorted)
+	}
+
+	invalidated := n2.Invalidates()
+	if len(invalidated) != 3 || findNode(invalidated, n1) < 0 || findNode(invalidated, n3) < 0 || findNode(invalidated, n4) < 0 {
+		t.Errorf("Expected node 1, 3 and 4 to be invalidated, got %v", invalidated)
+	}
+
+	if findNode(n3.Invalidates(), n2) >= 0 {
+		t.Errorf("Order-only edges should not invalidate")
+	}
+
+	walked := 0
+	n2.Walk(NewDepthFirstWalker(func(*Node, *Edge) {
+		walked++
+	}).OnlyKinds(HardEdge))
+	if walked != 0 {
+		t.Errorf("Only hard edges should be walked")
+	}
+
+	sorted, err = NewTopologicalSort().OnlyKinds(HardEdge).Sort([]*Node{n1, n2, n3})
+	if err != nil || findNode(sorted, n2) > findNode(sorted, n1) {
+		t.Errorf("Unexpected order %v", sorted)
+	}
+}
+
 // Determine if the order of the topological sort is correct.
 // For each node at position i, each of its dependencies must be
 // at a position lower than it self.
@@ -558,3 +631,120 @@ func findNode(l []*Node, n *Node) int {
 
 	return -1
 }
+
+func TestGraph_multigraph(t *testing.T) {
+	g := NewGraph()
+	g.Multigraph = true
+
+	n1 := g.NewNode(1)
+	n2 := g.NewNode(2)
+
+	e1 := n1.DependOnLabeled(n2, "import")
+	e2 := n1.DependOnLabeled(n2, "call")
+	e3 := n1.DependOnLabeled(n2, "call")
+	n1.DependOn(n2)
+
+	if e1 == e2 || e2 != e3 {
+		t.Errorf("Edges with distinct labels should coexist and duplicates should collapse")
+	}
+
+	if len(n1.EdgesTo(n2)) != 3 {
+		t.Errorf("Expected 3 parallel edges, got %v", len(n1.EdgesTo(n2)))
+	}
+
+	if n1.EdgeTo(n2, "import") != e1 {
+		t.Errorf("Unable to find the labeled edge")
+	}
+
+	n1.RemoveLabeledDependency(n2, "import")
+	if n1.EdgeTo(n2, "import") != nil || len(n1.EdgesTo(n2)) != 2 || len(n2.Edges) != 2 {
+		t.Errorf("The labeled edge was not removed")
+	}
+}
+
+func TestGraph_selfLoops(t *testing.T) {
+	g := NewGraph()
+
+	n1 := g.NewNode(1)
+	n2 := g.NewNode(2)
+
+	if n1.DependOn(n1) != nil {
+		t.Errorf("Self-loops should be refused by default")
+	}
+
+	g.AllowSelfLoops = true
+	loop := n1.DependOn(n1)
+	n1.DependOn(n2)
+
+	if loop == nil || len(n1.Edges) != 2 || n1.DependOn(n1) != loop {
+		t.Fatalf("Expected a single self-loop")
+	}
+
+	if !g.HasCyclicDependencies() {
+		t.Errorf("A self-loop should be a cyclic dependency")
+	}
+
+	cycles := g.Cycles()
+	if len(cycles) != 1 || len(cycles[0]) != 1 || cycles[0][0] != n1 {
+		t.Errorf("Expected a trivial cycle, got %v", cycles)
+	}
+
+	if _, err := g.TopologicalSort(nil); err == nil {
+		t.Errorf("Sorting a graph with a self-loop should fail")
+	}
+
+	if !n1.DependsOn(n2) || n1.DistanceTo(n2) < 0 || len(n1.GetDependencies(true, true)) != 2 {
+		t.Errorf("Queries should terminate and include the self-loop")
+	}
+
+	walked := 0
+	n1.Walk(NewDepthFirstWalker(func(*Node, *Edge) {
+		walked++
+	}))
+	if walked != 2 {
+		t.Errorf("Expected to walk 2 edges, walked %v", walked)
+	}
+
+	n1.RemoveDependency(n1)
+	if len(n1.Edges) != 1 || g.HasCyclicDependencies() {
+		t.Errorf("The self-loop was not removed")
+	}
+}
+
+func TestGraph_keyFunc(t *testing.T) {
+	type record struct {
+		id   string
+		tags []string
+	}
+
+	g := NewGraph()
+	g.KeyFunc = func(data interface{}) interface{} {
+		return data.(*record).id
+	}
+
+	n1 := g.NewNode(&record{"a", []string{"x"}})
+	n2 := g.NewNode(&record{"b", nil})
+	if g.NewNode(&record{"a", nil}) != n1 || g.Find(&record{id: "a"}) != n1 || g.Size() != 2 {
+		t.Errorf("Expected records with the same id to be the same node")
+	}
+
+	if n1.Data.(*record).tags[0] != "x" {
+		t.Errorf("Expected the full payload to be stored")
+	}
+
+	n1.DependOn(n2)
+	other := NewGraph()
+	other.KeyFunc = g.KeyFunc
+	other.NewNode(&record{"a", nil}).DependOn(other.NewNode(&record{"b", nil}))
+	if d := Diff(g, other); !d.IsEmpty() {
+		t.Errorf("Expected no difference, got %v", d)
+	}
+
+	slices := NewGraph()
+	slices.KeyFunc = func(data interface{}) interface{} {
+		return fmt.Sprint(data)
+	}
+	if slices.NewNode([]int{1, 2}) != slices.NewNode([]int{1, 2}) {
+		t.Errorf("Expected equal slices to be the same node")
+	}
+}
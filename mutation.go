@@ -0,0 +1,50 @@
+package graph
+
+import "errors"
+
+var (
+	// ErrSelfLoop is returned for edges from a node to itself, unless self-loops are allowed
+	ErrSelfLoop = errors.New("graph: self-loop")
+
+	// ErrDuplicateEdge is returned when the edge already exists
+	ErrDuplicateEdge = errors.New("graph: duplicate edge")
+
+	// ErrWouldCreateCycle is returned when an acyclic edge would close a cycle
+	ErrWouldCreateCycle = errors.New("graph: edge would create a cycle")
+
+	// ErrForeignNode is returned when a node does not belong to the graph
+	ErrForeignNode = errors.New("graph: node does not belong to the graph")
+
+	// ErrRuleViolation is returned when the graph enforces rules the edge violates
+	ErrRuleViolation = errors.New("graph: rule violation")
+)
+
+// EdgeOptions configures an edge added with AddEdge
+type EdgeOptions struct {
+	// Kind is the kind of the edge, HardEdge if zero
+	Kind EdgeKind
+
+	// Label distinguishes parallel edges in multigraph mode
+	Label interface{}
+
+	// Data is the data of the edge
+	Data interface{}
+
+	// Acyclic refuses the edge if it would close a cycle of ordering edges
+	Acyclic bool
+}
+
+// AddEdge inserts an edge from the dependent node to its dependency.
+// Unlike DependOn, no callbacks are involved in refusing the edge; the reason is returned as an error.
+// For duplicates, the existing edge is returned along with ErrDuplicateEdge.
+func (g *Graph) AddEdge(from, to *Node, opts *EdgeOptions) (*Edge, error) {
+	if from == nil || to == nil || from.graph != g || to.graph != g {
+		return nil, ErrForeignNode
+	}
+
+	if opts == nil {
+		opts = &EdgeOptions{}
+	}
+
+	return from.connect(to, opts)
+}
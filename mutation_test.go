@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGraph_addEdge(t *testing.T) {
+	g := NewGraph()
+	n1 := g.NewNode(1)
+	n2 := g.NewNode(2)
+	n3 := g.NewNode(3)
+
+	sameNode := false
+	g.OnSameNodeEdge = func(*Node) {
+		sameNode = true
+	}
+
+	e, err := g.AddEdge(n1, n2, &EdgeOptions{Data: "a", Kind: SoftEdge})
+	if err != nil || e.Data != "a" || e.Kind != SoftEdge || !n1.DependsOn(n2) {
+		t.Fatalf("Expected a soft edge, got %v, %v", e, err)
+	}
+
+	if d, err := g.AddEdge(n1, n2, nil); d != e || !errors.Is(err, ErrDuplicateEdge) {
+		t.Errorf("Expected the existing edge and ErrDuplicateEdge, got %v, %v", d, err)
+	}
+
+	if _, err := g.AddEdge(n1, n1, nil); !errors.Is(err, ErrSelfLoop) || sameNode {
+		t.Errorf("Expected ErrSelfLoop without callbacks, got %v", err)
+	}
+
+	other := NewGraph().NewNode(4)
+	if _, err := g.AddEdge(n1, other, nil); !errors.Is(err, ErrForeignNode) {
+		t.Errorf("Expected ErrForeignNode, got %v", err)
+	}
+
+	g.AddEdge(n2, n3, nil)
+	// The soft edge from 1 to 2 imposes no order
+	if _, err := g.AddEdge(n3, n1, &EdgeOptions{Acyclic: true}); err != nil {
+		t.Errorf("Expected the edge closing a soft cycle to be accepted, got %v", err)
+	}
+
+	n4 := g.NewNode(4)
+	g.AddEdge(n4, n2, nil)
+	if _, err := g.AddEdge(n2, n4, &EdgeOptions{Acyclic: true}); !errors.Is(err, ErrWouldCreateCycle) {
+		t.Errorf("Expected ErrWouldCreateCycle, got %v", err)
+	}
+	if n2.DependsOnAdjacent(n4) != nil {
+		t.Errorf("The cyclic edge should not be inserted")
+	}
+
+	n3.PutIntoRegion("a")
+	n4.PutIntoRegion("b")
+	g.Rules = NewRuleSet().Deny("a", "b")
+	g.Rules.Enforce = true
+	if _, err := g.AddEdge(n3, n4, nil); !errors.Is(err, ErrRuleViolation) {
+		t.Errorf("Expected ErrRuleViolation, got %v", err)
+	}
+}
@@ -16,15 +16,20 @@ type Node struct {
 	// Region defines which region the node belongs to
 	Region interface{}
 
-	// Some data that can be piggy backed on the node
+	// Some data that can be piggy backed on the node, see also Attrs
 	Metadata interface{}
 
+	// attrs are the attributes of the node, created on demand
+	attrs *Attributes
+
 	// An internal reference to the graph the node is attached to
 	graph *Graph
 
 	// For topological sort
-	mark      topologicalMark
-	localSort bool
+	mark topologicalMark
+
+	// order is the position in the maintained topological order
+	order int
 }
 
 func newNode(data interface{}) *Node {
@@ -33,48 +38,142 @@ func newNode(data interface{}) *Node {
 	}
 }
 
+// PutIntoRegion moves the node into the given region, removing it from its previous region.
+// Putting a node into the nil region removes it from its region.
+// The CrossRegion flag of the edges of the node is updated accordingly.
 func (n *Node) PutIntoRegion(region interface{}) *Node {
-	n.Region = region
-	n.graph.Regions[region] = append(n.graph.Regions[region], n)
+	if n.Region != region {
+		n.setRegion(region)
+	}
 	return n
 }
 
-// DependOn inserts the other node as a dependency for this node
+// RemoveFromRegion removes the node from its region
+func (n *Node) RemoveFromRegion() *Node {
+	return n.PutIntoRegion(nil)
+}
+
+func (n *Node) setRegion(region interface{}) {
+	from := n.Region
+	if from != nil {
+		n.graph.removeRegionMember(from, n)
+	}
+
+	var indexed []*Edge
+	for _, e := range n.Edges {
+		if n.graph.isIndexed(e) {
+			n.graph.countRegionEdge(e, -1)
+			indexed = append(indexed, e)
+		}
+	}
+
+	n.Region = region
+	if region != nil {
+		n.graph.Regions[region] = append(n.graph.Regions[region], n)
+	}
+
+	for _, e := range n.Edges {
+		e.updateCrossRegion()
+	}
+	for _, e := range indexed {
+		n.graph.countRegionEdge(e, 1)
+	}
+
+	if n.graph.OnRegionChanged != nil {
+		n.graph.OnRegionChanged(n, from, region)
+	}
+}
+
+// DependOn inserts the other node as a hard dependency for this node.
+// If the graph enforces rules, nil is returned for edges violating them.
 func (n *Node) DependOn(other *Node) *Edge {
-	if n == other {
+	return n.DependOnKind(other, HardEdge)
+}
+
+// DependOnKind inserts the other node as a dependency of the given kind for this node.
+// If the dependency already exists, the existing edge is returned unchanged.
+func (n *Node) DependOnKind(other *Node, kind EdgeKind) *Edge {
+	return n.dependOn(other, kind, nil)
+}
+
+// DependOnLabeled inserts the other node as a labeled dependency for this node.
+// In multigraph mode, edges with distinct labels between the same nodes coexist.
+// Otherwise, the label is only set if a new edge is created.
+func (n *Node) DependOnLabeled(other *Node, label interface{}) *Edge {
+	return n.dependOn(other, HardEdge, label)
+}
+
+func (n *Node) dependOn(other *Node, kind EdgeKind, label interface{}) *Edge {
+	edge, err := n.connect(other, &EdgeOptions{Kind: kind, Label: label})
+	switch err {
+	case ErrSelfLoop:
 		if n.graph.OnSameNodeEdge != nil {
 			n.graph.OnSameNodeEdge(n)
 		}
-		return nil
-	}
-	d := n.DependsOnAdjacent(other)
-	if d != nil {
+	case ErrDuplicateEdge:
 		if n.graph.OnDuplicateEdge != nil {
-			n.graph.OnDuplicateEdge(d)
+			n.graph.OnDuplicateEdge(edge)
 		}
-		return d
 	}
 
-	// The source points to destination
-	edge := &Edge{
-		// The dependent
-		Source: n,
-		// The dependency
-		Destination: other,
+	return edge
+}
+
+// connect creates an edge from this node to the other node, unless the edge is refused.
+// For duplicates, the existing edge is returned along with ErrDuplicateEdge.
+func (n *Node) connect(other *Node, opts *EdgeOptions) (*Edge, error) {
+	if n == other && !n.graph.AllowSelfLoops {
+		return nil, ErrSelfLoop
 	}
 
-	if n.graph.OnEdgeCreated != nil {
-		n.graph.OnEdgeCreated(edge)
+	if d := n.duplicateOf(other, opts.Label); d != nil {
+		return d, ErrDuplicateEdge
 	}
 
-	// Insert the edge into node 1 and node 2
-	n.Edges = append(n.Edges, edge)
-	other.Edges = append(other.Edges, edge)
+	if !opts.Kind.Orders() || n.graph.order == nil {
+		if opts.Acyclic && opts.Kind.Orders() {
+			if path := other.pathTo(n); path != nil {
+				return nil, &CycleError{Cycle: append([]*Node{n}, path[:len(path)-1]...)}
+			}
+		}
 
-	return edge
+		return n.link(other, opts)
+	}
+
+	forward, backward, cycle := n.graph.affected(n, other)
+	if cycle != nil {
+		if opts.Acyclic || n.graph.acyclic {
+			return nil, &CycleError{Cycle: cycle}
+		}
+
+		// There is no order to keep until the cycle is broken, see Order
+		edge, err := n.link(other, opts)
+		if err == nil {
+			n.graph.order = nil
+		}
+		return edge, err
+	}
+
+	edge, err := n.link(other, opts)
+	if err == nil {
+		n.graph.reorder(forward, backward)
+	}
+
+	return edge, err
 }
 
-// DependOn2 does a dependency check before adding the edge
+// duplicateOf returns the existing edge an edge to the other node with the given label would duplicate
+func (n *Node) duplicateOf(other *Node, label interface{}) *Edge {
+	if n.graph.Multigraph {
+		return n.EdgeTo(other, label)
+	}
+
+	return n.DependsOnAdjacent(other)
+}
+
+// DependOn2 does a dependency check before adding the edge.
+// If this node already depends on the other node, the adjacent edge, if any, is returned.
+// If the other node depends on this node, the edge would close a cycle and nil is returned.
 func (n *Node) DependOn2(other *Node) *Edge {
 	if n == other {
 		return nil
@@ -83,44 +182,91 @@ func (n *Node) DependOn2(other *Node) *Edge {
 		return n.DependsOnAdjacent(other)
 	}
 
+	edge, _ := n.connect(other, &EdgeOptions{Acyclic: true})
+	return edge
+}
+
+// link creates an edge from this node to the other node.
+// Edges violating enforced rules are refused with ErrRuleViolation.
+func (n *Node) link(other *Node, opts *EdgeOptions) (*Edge, error) {
+	// The source points to destination
 	edge := &Edge{
 		// The dependent
 		Source: n,
 		// The dependency
 		Destination: other,
+		Data:        opts.Data,
+		Kind:        opts.Kind,
+		Label:       opts.Label,
+	}
+	edge.updateCrossRegion()
+
+	if n.graph.Rules != nil {
+		violations := n.graph.Rules.checkNewEdge(n.graph, edge)
+		if n.graph.OnRuleViolation != nil {
+			for _, v := range violations {
+				n.graph.OnRuleViolation(v)
+			}
+		}
+
+		if n.graph.Rules.Enforce && len(violations) > 0 {
+			return nil, fmt.Errorf("%w: %v", ErrRuleViolation, violations[0])
+		}
 	}
 
 	if n.graph.OnEdgeCreated != nil {
 		n.graph.OnEdgeCreated(edge)
 	}
 
-	// Insert the edge into node 1 and node 2
+	// Insert the edge into node 1 and node 2, once for self-loops
+	n.graph.indexEdge(edge)
 	n.Edges = append(n.Edges, edge)
-	other.Edges = append(other.Edges, edge)
+	if other != n {
+		other.Edges = append(other.Edges, edge)
+	}
 
-	return edge
+	return edge, nil
 }
 
 // RemoveDependency will remove the given dependency for this node.
+// In multigraph mode, all edges to the other node are removed.
+// In undirected mode, the edges in both directions are removed.
 func (n *Node) RemoveDependency(other *Node) {
 	i := 0
 	for _, edge := range n.Edges {
-		if edge.Source != n || edge.Destination != other {
+		if !edge.connects(n, other) {
 			n.Edges[i] = edge
 			i++
 			continue
 		}
 
-		other.RemoveEdge(edge)
+		if edge.Source == n {
+			n.graph.unindexEdge(edge)
+		}
+		if other != n {
+			other.RemoveEdge(edge)
+		}
 	}
 
 	n.Edges = n.Edges[:i]
 }
 
+// RemoveLabeledDependency will remove the edge with the given label to the other node.
+func (n *Node) RemoveLabeledDependency(other *Node, label interface{}) {
+	if e := n.EdgeTo(other, label); e != nil {
+		e.Remove()
+	}
+}
+
 // RemoveEdge will remove a given edge from the node.
 // Note, that this will not remove the edge from the other node.
 // Use edge.Remove() instead.
 func (n *Node) RemoveEdge(e *Edge) {
+	// The index follows the edges of the source
+	if e.Source == n {
+		n.graph.unindexEdge(e)
+	}
+
 	i := 0
 	for _, edge := range n.Edges {
 		if edge != e {
@@ -143,7 +289,7 @@ func (n *Node) DependsOn(other *Node) bool {
 			return true
 		}
 
-		if edge.Destination.DependsOn(other) {
+		if edge.Destination != n && edge.Destination.DependsOn(other) {
 			return true
 		}
 	}
@@ -151,14 +297,48 @@ func (n *Node) DependsOn(other *Node) bool {
 	return false
 }
 
+// reaches returns true if there is a path of outbound edges from this node to the other node.
+// Soft edges are ignored.
+func (n *Node) reaches(other *Node) bool {
+	visited := map[*Node]bool{n: true}
+	queue := []*Node{n}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, edge := range current.Edges {
+			if edge.Source != current || !edge.Kind.Orders() {
+				continue
+			}
+
+			if edge.Destination == other {
+				return true
+			}
+
+			if !visited[edge.Destination] {
+				visited[edge.Destination] = true
+				queue = append(queue, edge.Destination)
+			}
+		}
+	}
+
+	return false
+}
+
 // DependsOnAdjacent will return true if this node is dependent and adjacent to the other node.
+// In undirected mode, the direction of the edge is ignored.
 func (n *Node) DependsOnAdjacent(other *Node) *Edge {
-	for _, edge := range n.Edges {
-		if edge.Source != n {
-			continue
-		}
+	if edges := n.graph.edgesBetween(n, other); len(edges) > 0 {
+		return edges[0]
+	}
 
-		if edge.Destination == other {
+	return nil
+}
+
+// EdgeTo returns the edge from this node to the other node with the given label, or nil.
+// In undirected mode, the direction of the edge is ignored.
+func (n *Node) EdgeTo(other *Node, label interface{}) *Edge {
+	for _, edge := range n.graph.edgesBetween(n, other) {
+		if edge.Label == label {
 			return edge
 		}
 	}
@@ -166,6 +346,32 @@ func (n *Node) DependsOnAdjacent(other *Node) *Edge {
 	return nil
 }
 
+// EdgesTo returns all edges from this node to the other node.
+// In undirected mode, the direction of the edges is ignored.
+func (n *Node) EdgesTo(other *Node) (edges []*Edge) {
+	return append(edges, n.graph.edgesBetween(n, other)...)
+}
+
+// Connect connects this node with the other node.
+// It is the same as DependOn, but reads better for undirected graphs.
+func (n *Node) Connect(other *Node) *Edge {
+	return n.DependOn(other)
+}
+
+// Neighbors returns the nodes adjacent to this node, regardless of the direction of the edges.
+func (n *Node) Neighbors() (neighbors []*Node) {
+	seen := make(map[*Node]bool)
+	for _, edge := range n.Edges {
+		other := edge.Other(n)
+		if !seen[other] {
+			seen[other] = true
+			neighbors = append(neighbors, other)
+		}
+	}
+
+	return
+}
+
 // DistanceTo will return the distance from one node to another in terms of edges in between.
 // Zero means no dependency.
 func (n *Node) DistanceTo(other *Node) int {
@@ -181,6 +387,10 @@ func (n *Node) DistanceTo(other *Node) int {
 			shortest = 1
 		}
 
+		if edge.Destination == n {
+			continue
+		}
+
 		d := edge.Destination.DistanceTo(other) + 1
 		if d < shortest {
 			hasShortest = true
@@ -202,7 +412,9 @@ func (n *Node) DependencyLength() (c int) {
 			continue
 		}
 
-		c += e.Destination.DependencyLength()
+		if e.Destination != n {
+			c += e.Destination.DependencyLength()
+		}
 		c++
 	}
 
@@ -213,7 +425,7 @@ func (n *Node) DependencyLength() (c int) {
 // dependent on this node.
 func (n *Node) IsDependency() bool {
 	for _, e := range n.Edges {
-		if e.Destination != n {
+		if e.Destination != n || e.Source == n {
 			continue
 		}
 
@@ -234,7 +446,7 @@ func (n *Node) GetDependencies(unique bool, all bool) (deps []*Node) {
 		}
 
 		deps = append(deps, edge.Destination)
-		if all {
+		if all && edge.Destination != n {
 			deps = append(deps, edge.Destination.GetDependencies(unique, all)...)
 		}
 	}
@@ -272,7 +484,7 @@ func (n *Node) GetDependents(unique bool, all bool) (deps []*Node) {
 		}
 
 		deps = append(deps, edge.Source)
-		if all {
+		if all && edge.Source != n {
 			deps = append(deps, edge.Source.GetDependents(unique, all)...)
 		}
 	}
@@ -299,10 +511,32 @@ func (n *Node) GetDependents(unique bool, all bool) (deps []*Node) {
 	return
 }
 
+// Invalidates returns the nodes invalidated by a change to this node, which are
+// its direct and indirect dependents over edges whose kind invalidates.
+func (n *Node) Invalidates() (nodes []*Node) {
+	visited := map[*Node]bool{n: true}
+	queue := []*Node{n}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, e := range current.Edges {
+			if e.Destination != current || !e.Kind.Invalidates() || visited[e.Source] {
+				continue
+			}
+
+			visited[e.Source] = true
+			nodes = append(nodes, e.Source)
+			queue = append(queue, e.Source)
+		}
+	}
+
+	return
+}
+
 func (n *Node) hasCyclicDependency(deps []*Node) bool {
 	deps = append(deps, n)
 	for _, e := range n.Edges {
-		if e.Source != n {
+		if e.Source != n || !e.Kind.Orders() {
 			continue
 		}
 
@@ -325,5 +559,5 @@ func (n Node) String() string {
 }
 
 func (n Node) Stringify() string {
-	return n.graph.NodeStringer(n.Data)
+	return n.graph.label(&n)
 }
@@ -0,0 +1,235 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CycleError is returned for edges that would close a cycle
+type CycleError struct {
+	// Cycle starts at the dependent of the refused edge, each node depending on the next
+	// and the last node depending on the first
+	Cycle []*Node
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrWouldCreateCycle, e.Cycle)
+}
+
+// Is makes the error match ErrWouldCreateCycle
+func (e *CycleError) Is(target error) bool {
+	return target == ErrWouldCreateCycle
+}
+
+// EnableAcyclic refuses edges of ordering kinds that would close a cycle from now on.
+// The graph keeps a topological order, which is adjusted locally for every new edge,
+// so only the nodes between the ends of the edge in the order are visited.
+// It fails if the graph is undirected or already has a cycle.
+func (g *Graph) EnableAcyclic() error {
+	if g.Undirected {
+		return fmt.Errorf("an undirected graph cannot be acyclic")
+	}
+
+	if err := g.initOrder(); err != nil {
+		if cycles := g.Cycles(); len(cycles) > 0 {
+			return &CycleError{Cycle: cycleWithin(cycles[0])}
+		}
+		return err
+	}
+
+	g.acyclic = true
+	return nil
+}
+
+// Order returns the nodes such that dependencies come before their dependents.
+// The order is computed on the first call, and then kept up to date as edges are
+// added, adjusting it locally for every new edge. Removing edges keeps the order valid.
+// If an edge closes a cycle, the order is dropped and computed again on the next call,
+// failing as long as the graph has a cycle.
+func (g *Graph) Order() ([]*Node, error) {
+	if g.Undirected {
+		return nil, fmt.Errorf("an undirected graph cannot be sorted topologically")
+	}
+
+	if g.order == nil {
+		if err := g.initOrder(); err != nil {
+			return nil, err
+		}
+	}
+
+	order := make([]*Node, len(g.order))
+	copy(order, g.order)
+	return order, nil
+}
+
+// initOrder sorts the graph and numbers the nodes by their position
+func (g *Graph) initOrder() error {
+	sorted, err := SortTopological(g.sortedNodes())
+	if err != nil {
+		return err
+	}
+
+	// An empty order is kept as well
+	g.order = make([]*Node, 0, len(sorted))
+	for i, n := range sorted {
+		n.order = i
+		g.order = append(g.order, n)
+	}
+
+	return nil
+}
+
+// appendOrder places a new node last in the topological order, if one is kept
+func (g *Graph) appendOrder(n *Node) {
+	if g.order == nil {
+		return
+	}
+
+	n.order = len(g.order)
+	g.order = append(g.order, n)
+}
+
+// affected returns the nodes to reorder before the dependent can depend on the dependency,
+// or the would-be cycle. Following Pearce and Kelly, the dependents of the dependent and
+// the dependencies of the dependency are searched, but only between their positions.
+func (g *Graph) affected(dependent, dependency *Node) (forward, backward, cycle []*Node) {
+	if dependent == dependency {
+		return nil, nil, []*Node{dependent}
+	}
+
+	lower, upper := dependent.order, dependency.order
+	if upper < lower {
+		return nil, nil, nil
+	}
+
+	// Search the dependents, remembering how they were reached
+	parents := map[*Node]*Node{dependent: nil}
+	stack := []*Node{dependent}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		forward = append(forward, current)
+
+		for _, e := range current.Edges {
+			if e.Destination != current || e.Source == current || !e.Kind.Orders() {
+				continue
+			}
+
+			next := e.Source
+			if _, visited := parents[next]; visited || next.order > upper {
+				continue
+			}
+			parents[next] = current
+
+			if next == dependency {
+				cycle = []*Node{dependent}
+				for n := dependency; n != dependent; n = parents[n] {
+					cycle = append(cycle, n)
+				}
+				return nil, nil, cycle
+			}
+
+			stack = append(stack, next)
+		}
+	}
+
+	visited := map[*Node]bool{dependency: true}
+	stack = []*Node{dependency}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		backward = append(backward, current)
+
+		for _, e := range current.Edges {
+			if e.Source != current || e.Destination == current || !e.Kind.Orders() {
+				continue
+			}
+
+			next := e.Destination
+			if visited[next] || next.order < lower {
+				continue
+			}
+			visited[next] = true
+			stack = append(stack, next)
+		}
+	}
+
+	return forward, backward, nil
+}
+
+// reorder moves the affected dependencies before the affected dependents,
+// reusing their positions and keeping the relative order within each group.
+func (g *Graph) reorder(forward, backward []*Node) {
+	byOrder := func(nodes []*Node) {
+		sort.Slice(nodes, func(i, j int) bool {
+			return nodes[i].order < nodes[j].order
+		})
+	}
+	byOrder(forward)
+	byOrder(backward)
+
+	nodes := append(backward, forward...)
+	positions := make([]int, len(nodes))
+	for i, n := range nodes {
+		positions[i] = n.order
+	}
+	sort.Ints(positions)
+
+	for i, n := range nodes {
+		n.order = positions[i]
+		g.order[n.order] = n
+	}
+}
+
+// cycleWithin returns a cycle through the first node of a cyclic strongly connected component,
+// each node depending on the next and the last node depending on the first
+func cycleWithin(component []*Node) []*Node {
+	members := make(map[*Node]bool, len(component))
+	for _, m := range component {
+		members[m] = true
+	}
+
+	n := component[0]
+	for _, e := range n.Edges {
+		if e.Source != n || !e.Kind.Orders() || !members[e.Destination] {
+			continue
+		}
+
+		// The dependency is in the component, so there is a path back
+		path := e.Destination.pathTo(n)
+		return append([]*Node{n}, path[:len(path)-1]...)
+	}
+
+	return component
+}
+
+// pathTo returns the nodes on a shortest path of ordering edges from this node to the other node,
+// both included, or nil if there is none.
+func (n *Node) pathTo(other *Node) []*Node {
+	parents := map[*Node]*Node{n: nil}
+	queue := []*Node{n}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == other {
+			var path []*Node
+			for p := other; p != nil; p = parents[p] {
+				path = append([]*Node{p}, path...)
+			}
+			return path
+		}
+
+		for _, edge := range current.Edges {
+			if edge.Source != current || !edge.Kind.Orders() {
+				continue
+			}
+
+			if _, visited := parents[edge.Destination]; !visited {
+				parents[edge.Destination] = current
+				queue = append(queue, edge.Destination)
+			}
+		}
+	}
+
+	return nil
+}
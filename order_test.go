@@ -0,0 +1,226 @@
+package graph
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// checkOrder fails if a dependency does not come before its dependent in the maintained order
+func checkOrder(t *testing.T, g *Graph) {
+	t.Helper()
+
+	if len(g.order) != len(g.Nodes) {
+		t.Fatalf("Expected %v nodes in the order, got %v", len(g.Nodes), len(g.order))
+	}
+
+	for i, n := range g.order {
+		if n.order != i {
+			t.Fatalf("Node %v is at %v, but has position %v", n, i, n.order)
+		}
+
+		for _, e := range n.Edges {
+			if e.Source == n && e.Kind.Orders() && e.Destination.order >= n.order {
+				t.Fatalf("%v depends on %v, which comes later", n, e.Destination)
+			}
+		}
+	}
+}
+
+func TestGraph_enableAcyclicCycle(t *testing.T) {
+	g := NewGraph()
+	a, b, c, d := g.NewNode("a"), g.NewNode("b"), g.NewNode("c"), g.NewNode("d")
+	a.DependOn(b)
+	b.DependOn(c)
+	c.DependOn(a)
+	b.DependOn(d)
+	d.DependOn(b)
+
+	var cycleErr *CycleError
+	if err := g.EnableAcyclic(); !errors.As(err, &cycleErr) {
+		t.Fatalf("Expected a cycle error, got %v", err)
+	}
+
+	cycle := cycleErr.Cycle
+	if len(cycle) < 2 || len(cycle) > 3 {
+		t.Fatalf("Expected a simple cycle, got %v", cycle)
+	}
+	for i, n := range cycle {
+		if n.DependsOnAdjacent(cycle[(i+1)%len(cycle)]) == nil {
+			t.Errorf("%v does not depend on the next node in %v", n, cycle)
+		}
+	}
+}
+
+func TestGraph_enableAcyclic(t *testing.T) {
+	g := NewGraph()
+	n1 := g.NewNode(1)
+	n2 := g.NewNode(2)
+	n3 := g.NewNode(3)
+	n4 := g.NewNode(4)
+
+	n1.DependOn(n2)
+	n2.DependOn(n1)
+	var cycleErr *CycleError
+	if err := g.EnableAcyclic(); !errors.As(err, &cycleErr) || len(cycleErr.Cycle) != 2 {
+		t.Fatalf("Expected the existing cycle, got %v", err)
+	}
+
+	n2.RemoveDependency(n1)
+	if err := g.EnableAcyclic(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	// 1 -> 2, then 3 -> 1 and 4 -> 3 reordering the nodes
+	n3.DependOn(n1)
+	n4.DependOn(n3)
+	checkOrder(t, g)
+
+	n5 := g.NewNode(5)
+	n2.DependOn(n5)
+	checkOrder(t, g)
+
+	_, err := g.AddEdge(n5, n4, nil)
+	if !errors.Is(err, ErrWouldCreateCycle) || !errors.As(err, &cycleErr) {
+		t.Fatalf("Expected a cycle error, got %v", err)
+	}
+
+	expected := []*Node{n5, n4, n3, n1, n2}
+	if len(cycleErr.Cycle) != len(expected) {
+		t.Fatalf("Expected the cycle %v, got %v", expected, cycleErr.Cycle)
+	}
+	for i, n := range expected {
+		if cycleErr.Cycle[i] != n {
+			t.Fatalf("Expected the cycle %v, got %v", expected, cycleErr.Cycle)
+		}
+	}
+
+	if n5.DependOn(n4) != nil || len(n5.Edges) != 1 {
+		t.Errorf("The cyclic edge should be refused")
+	}
+
+	// Soft edges impose no order
+	if _, err := g.AddEdge(n5, n4, &EdgeOptions{Kind: SoftEdge}); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	checkOrder(t, g)
+}
+
+func TestGraph_enableAcyclicRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	g := NewGraph()
+	for i := 0; i < 50; i++ {
+		g.NewNode(i)
+	}
+	if err := g.EnableAcyclic(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	for i := 0; i < 500; i++ {
+		from, to := g.Find(rnd.Intn(50)), g.Find(rnd.Intn(50))
+		if from == to {
+			continue
+		}
+
+		closes := to.pathTo(from) != nil
+		edge, err := g.AddEdge(from, to, nil)
+		if closes != errors.Is(err, ErrWouldCreateCycle) {
+			t.Fatalf("Edge from %v to %v closes a cycle: %v, got %v", from, to, closes, err)
+		}
+		if closes && edge != nil {
+			t.Fatalf("The cyclic edge from %v to %v was inserted", from, to)
+		}
+	}
+
+	checkOrder(t, g)
+	if len(g.Cycles()) > 0 {
+		t.Errorf("The graph should be acyclic")
+	}
+}
+
+func TestNode_dependOn2(t *testing.T) {
+	g := NewGraph()
+	n1 := g.NewNode(1)
+	n2 := g.NewNode(2)
+	n3 := g.NewNode(3)
+
+	n1.DependOn(n2)
+	n2.DependOn(n3)
+
+	if n3.DependOn2(n1) != nil || len(n3.Edges) != 1 {
+		t.Errorf("An edge closing a cycle should be refused")
+	}
+
+	if n1.DependOn2(n3) != nil || len(n1.Edges) != 1 {
+		t.Errorf("A transitive dependency should not be added")
+	}
+
+	if e := n1.DependOn2(n2); e == nil || e.Destination != n2 {
+		t.Errorf("Expected the existing edge, got %v", e)
+	}
+}
+
+func TestGraph_order(t *testing.T) {
+	g := NewGraph()
+	n1 := g.NewNode(1)
+	n2 := g.NewNode(2)
+	n3 := g.NewNode(3)
+
+	n1.DependOn(n2)
+	order, err := g.Order()
+	if err != nil || len(order) != 3 {
+		t.Fatalf("Expected 3 nodes, got %v, %v", order, err)
+	}
+
+	n2.DependOn(n3)
+	n4 := g.NewNode(4)
+	n3.DependOn(n4)
+	checkOrder(t, g)
+
+	order, _ = g.Order()
+	if order[0] != n4 || order[3] != n1 {
+		t.Errorf("Expected 4 first and 1 last, got %v", order)
+	}
+
+	e := n4.DependOn(n1)
+	if e == nil {
+		t.Fatalf("The cyclic edge should be inserted outside acyclic mode")
+	}
+	if _, err := g.Order(); err == nil {
+		t.Errorf("Expected an error for a cyclic graph")
+	}
+
+	e.Remove()
+	if _, err := g.Order(); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	checkOrder(t, g)
+
+	n1.RemoveDependency(n2)
+	n2.DependOn(n1)
+	checkOrder(t, g)
+}
+
+func TestGraph_orderRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	g := NewGraph()
+	g.Order()
+
+	for i := 0; i < 500; i++ {
+		from, to := g.NewNode(rnd.Intn(60)), g.NewNode(rnd.Intn(60))
+		if from == to || to.pathTo(from) != nil {
+			continue
+		}
+
+		kind := HardEdge
+		if rnd.Intn(4) == 0 {
+			kind = SoftEdge
+		}
+		from.DependOnKind(to, kind)
+		if rnd.Intn(10) == 0 {
+			from.Edges[0].Remove()
+		}
+	}
+
+	checkOrder(t, g)
+}
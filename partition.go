@@ -0,0 +1,312 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PartitionOptions configures PartitionK
+type PartitionOptions struct {
+	// Weight returns the weight of a node, 1 if nil
+	Weight func(*Node) float64
+
+	// Imbalance is the allowed relative overweight of a group, 0.05 if zero
+	Imbalance float64
+
+	// Passes is the maximum number of refinement passes per level, 10 if zero
+	Passes int
+}
+
+// partitionLevel is one level of the multilevel partitioner
+type partitionLevel struct {
+	graph  *weightedGraph
+	weight []float64
+
+	// coarse maps the nodes of this level to the nodes of the next coarser level
+	coarse []int
+}
+
+// PartitionK splits the nodes into k groups of roughly equal weight while
+// minimizing the number of edges between groups, treating the edges as undirected.
+// The graph is coarsened by heavy edge matching, the coarsest graph is partitioned
+// greedily, and the partition is refined by moving boundary nodes with positive
+// gain at every level, in the spirit of Kernighan-Lin and Fiduccia-Mattheyses.
+func (g *Graph) PartitionK(k int, opts *PartitionOptions) (Partition, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("cannot partition into %v groups", k)
+	}
+
+	o := PartitionOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Imbalance <= 0 {
+		o.Imbalance = 0.05
+	}
+	if o.Passes <= 0 {
+		o.Passes = 10
+	}
+
+	wg := newWeightedGraph(g)
+	weights := make([]float64, len(wg.nodes))
+	total, heaviest := 0.0, 0.0
+	for i, n := range wg.nodes {
+		weights[i] = 1
+		if o.Weight != nil {
+			weights[i] = o.Weight(n)
+		}
+		if weights[i] < 0 {
+			return nil, fmt.Errorf("node %v has negative weight %v", n, weights[i])
+		}
+
+		total += weights[i]
+		if weights[i] > heaviest {
+			heaviest = weights[i]
+		}
+	}
+
+	maxLoad := total / float64(k) * (1 + o.Imbalance)
+	if maxLoad < heaviest {
+		maxLoad = heaviest
+	}
+
+	// Coarsen
+	levels := []*partitionLevel{{graph: wg, weight: weights}}
+	for {
+		level := levels[len(levels)-1]
+		size := len(level.weight)
+		if size <= 10*k {
+			break
+		}
+
+		next := level.coarsen(maxLoad / 2)
+		if len(next.weight) > size*9/10 {
+			break
+		}
+		levels = append(levels, next)
+	}
+
+	// Partition the coarsest level and refine while projecting back
+	coarsest := levels[len(levels)-1]
+	assignment := coarsest.initialPartition(k, maxLoad)
+	coarsest.refine(assignment, k, maxLoad, o.Passes)
+	for i := len(levels) - 2; i >= 0; i-- {
+		level := levels[i]
+		projected := make([]int, len(level.weight))
+		for u := range projected {
+			projected[u] = assignment[level.coarse[u]]
+		}
+
+		assignment = projected
+		level.refine(assignment, k, maxLoad, o.Passes)
+	}
+
+	p := make(Partition, len(wg.nodes))
+	for i, n := range wg.nodes {
+		p[n] = assignment[i]
+	}
+
+	return p, nil
+}
+
+// coarsen matches every node with its unmatched neighbor sharing the heaviest
+// edge, as long as their combined weight stays within the limit.
+func (l *partitionLevel) coarsen(limit float64) *partitionLevel {
+	size := len(l.weight)
+	match := make([]int, size)
+	for u := range match {
+		match[u] = -1
+	}
+
+	// Visit light nodes first, they are the hardest to match
+	order := make([]int, size)
+	for u := range order {
+		order[u] = u
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return l.weight[order[i]] < l.weight[order[j]]
+	})
+
+	for _, u := range order {
+		if match[u] >= 0 {
+			continue
+		}
+
+		match[u] = u
+		best, bestWeight := -1, 0.0
+		for _, nb := range l.graph.neighbors[u] {
+			v := nb.node
+			if match[v] >= 0 || l.weight[u]+l.weight[v] > limit {
+				continue
+			}
+
+			if nb.weight > bestWeight {
+				best, bestWeight = v, nb.weight
+			}
+		}
+
+		if best >= 0 {
+			match[u] = best
+			match[best] = u
+		}
+	}
+
+	community := make([]int, size)
+	for u := range community {
+		community[u] = u
+		if match[u] < u {
+			community[u] = match[u]
+		}
+	}
+
+	coarse, renumbered := l.graph.aggregate(community)
+	weights := make([]float64, len(coarse.neighbors))
+	for u, c := range renumbered {
+		weights[c] += l.weight[u]
+	}
+
+	l.coarse = renumbered
+	return &partitionLevel{graph: coarse, weight: weights}
+}
+
+// initialPartition grows one group at a time from a seed node, repeatedly
+// adding the unassigned node most connected to the group until it reaches
+// its share of the total weight. The last group takes the remaining nodes.
+func (l *partitionLevel) initialPartition(k int, maxLoad float64) []int {
+	size := len(l.weight)
+	assignment := make([]int, size)
+	assigned := make([]bool, size)
+
+	ideal := 0.0
+	for _, w := range l.weight {
+		ideal += w
+	}
+	ideal /= float64(k)
+
+	remaining := size
+	for c := 0; c < k-1 && remaining > 0; c++ {
+		load := 0.0
+		frontier := make(map[int]float64)
+		for load < ideal && remaining > 0 {
+			best := -1
+			for u, connection := range frontier {
+				if load+l.weight[u] > maxLoad {
+					continue
+				}
+				if best < 0 || connection > frontier[best] || (connection == frontier[best] && u < best) {
+					best = u
+				}
+			}
+
+			if best < 0 {
+				// Start from a new seed when the group cannot grow any further
+				for u := 0; u < size; u++ {
+					if !assigned[u] && load+l.weight[u] <= maxLoad {
+						best = u
+						break
+					}
+				}
+				if best < 0 {
+					break
+				}
+			}
+
+			assignment[best] = c
+			assigned[best] = true
+			load += l.weight[best]
+			remaining--
+			delete(frontier, best)
+			for _, nb := range l.graph.neighbors[best] {
+				if !assigned[nb.node] {
+					frontier[nb.node] += nb.weight
+				}
+			}
+		}
+	}
+
+	for u := range assignment {
+		if !assigned[u] {
+			assignment[u] = k - 1
+		}
+	}
+
+	return assignment
+}
+
+// refine moves nodes to the neighboring group with the best gain in cut weight,
+// as long as the move keeps the groups within the allowed load.
+func (l *partitionLevel) refine(assignment []int, k int, maxLoad float64, passes int) {
+	load := make([]float64, k)
+	for u, c := range assignment {
+		load[c] += l.weight[u]
+	}
+
+	connection := make([]float64, k)
+	for pass := 0; pass < passes; pass++ {
+		moved := false
+		for u, current := range assignment {
+			for c := range connection {
+				connection[c] = 0
+			}
+
+			boundary := false
+			for _, nb := range l.graph.neighbors[u] {
+				c := assignment[nb.node]
+				connection[c] += nb.weight
+				if c != current {
+					boundary = true
+				}
+			}
+
+			overloaded := load[current] > maxLoad
+			if !boundary && !overloaded {
+				continue
+			}
+
+			best, bestGain := current, 0.0
+			for c := 0; c < k; c++ {
+				if c == current || load[c]+l.weight[u] > maxLoad {
+					continue
+				}
+
+				gain := connection[c] - connection[current]
+				better := gain > bestGain
+				if !better && gain == bestGain {
+					// Prefer moves improving the balance when the cut is unchanged
+					better = load[c]+l.weight[u] < load[current] && (best == current || load[c] < load[best])
+				}
+				if overloaded && best == current {
+					better = true
+				}
+
+				if better {
+					best, bestGain = c, gain
+				}
+			}
+
+			if best != current {
+				assignment[u] = best
+				load[current] -= l.weight[u]
+				load[best] += l.weight[u]
+				moved = true
+			}
+		}
+
+		if !moved {
+			break
+		}
+	}
+}
+
+// EdgeCut returns the number of edges between nodes in different groups of the partition
+func (g *Graph) EdgeCut(p Partition) (cut int) {
+	for _, n := range g.Nodes {
+		for _, e := range n.Edges {
+			if e.Source == n && p[e.Source] != p[e.Destination] {
+				cut++
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,45 @@
+package graph
+
+import "testing"
+
+func TestGraph_partitionK(t *testing.T) {
+	g := NewGraph()
+
+	// A ring of 8 cliques with 6 nodes each
+	var nodes []*Node
+	for i := 0; i < 48; i++ {
+		nodes = append(nodes, g.NewNode(i))
+	}
+	for c := 0; c < 8; c++ {
+		for i := 0; i < 6; i++ {
+			for j := i + 1; j < 6; j++ {
+				nodes[c*6+i].DependOn(nodes[c*6+j])
+			}
+		}
+		nodes[c*6].DependOn(nodes[(c*6+6)%48])
+	}
+
+	p, err := g.PartitionK(4, nil)
+	if err != nil {
+		t.Fatalf("Unable to partition: %v", err)
+	}
+
+	groups := p.Groups()
+	if len(groups) != 4 {
+		t.Fatalf("Expected 4 groups, got %v", len(groups))
+	}
+
+	for _, group := range groups {
+		if len(group) < 11 || len(group) > 13 {
+			t.Errorf("Unbalanced group of %v nodes", len(group))
+		}
+	}
+
+	if cut := g.EdgeCut(p); cut > 8 {
+		t.Errorf("Expected a cut of at most 8 edges, got %v", cut)
+	}
+
+	if _, err := g.PartitionK(0, nil); err == nil {
+		t.Errorf("Partitioning into zero groups should fail")
+	}
+}
@@ -0,0 +1,195 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Formatter writes a textual representation of a graph
+type Formatter interface {
+	Format(w io.Writer, g *Graph) error
+}
+
+// FormatterFunc is an adapter allowing ordinary functions to be used as formatters
+type FormatterFunc func(w io.Writer, g *Graph) error
+
+// Format calls f(w, g)
+func (f FormatterFunc) Format(w io.Writer, g *Graph) error {
+	return f(w, g)
+}
+
+var (
+	// EdgeFormatter writes one line per edge
+	EdgeFormatter Formatter = FormatterFunc(formatEdges)
+
+	// NodeFormatter writes one line per node
+	NodeFormatter Formatter = FormatterFunc(formatNodes)
+
+	// DetailFormatter writes each node followed by its inbound and outbound edges
+	DetailFormatter Formatter = FormatterFunc(formatDetails)
+
+	// TreeFormatter writes the dependencies of the nodes without dependents as indented trees.
+	// Nodes already written are marked with (*) instead of being expanded again, and nodes only
+	// reachable through cycles start trees of their own.
+	TreeFormatter Formatter = FormatterFunc(formatTree)
+
+	// TableFormatter writes the nodes as an aligned table
+	TableFormatter Formatter = FormatterFunc(formatTable)
+)
+
+// errWriter remembers the first error occurring while writing
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, a ...interface{}) {
+	if ew.err != nil {
+		return
+	}
+
+	_, ew.err = fmt.Fprintf(ew.w, format, a...)
+}
+
+func formatEdges(w io.Writer, g *Graph) error {
+	ew := &errWriter{w: w}
+	for _, n := range g.sortedNodes() {
+		for _, e := range n.Edges {
+			if e.Source != n {
+				continue
+			}
+
+			ew.printf("[%v] %v -(%v)> [%v] %v\n", n, g.label(n), e.Data, e.Destination, g.label(e.Destination))
+		}
+	}
+
+	return ew.err
+}
+
+func formatNode(ew *errWriter, g *Graph, n *Node) {
+	if n.Data != nil {
+		ew.printf("[%v]: %v(%T)\n", n, g.label(n), n.Data)
+	} else {
+		ew.printf("[%v]: NIL(%T)\n", n, n.Data)
+	}
+}
+
+func formatNodes(w io.Writer, g *Graph) error {
+	ew := &errWriter{w: w}
+	for _, n := range g.sortedNodes() {
+		formatNode(ew, g, n)
+	}
+
+	return ew.err
+}
+
+func formatDetails(w io.Writer, g *Graph) error {
+	ew := &errWriter{w: w}
+	edges := make(map[*Edge]struct{ id, num int })
+	id := 0
+	edgeCounter := 0
+
+	for _, node := range g.sortedNodes() {
+		formatNode(ew, g, node)
+
+		for i, edge := range node.Edges {
+			edgeCounter++
+			value, hasEdge := edges[edge]
+			if !hasEdge {
+				value = struct{ id, num int }{id, 1}
+				edges[edge] = value
+				id++
+			} else {
+				value.num++
+				edges[edge] = value
+			}
+
+			inbound := edge.Destination == node
+			if inbound {
+				ew.printf("  [%v] %v <- %v [%v/%v]\n", i, edge.Destination.ID, edge.Source.ID, value.id, value.num)
+			} else {
+				ew.printf("  [%v] %v -> %v [%v/%v]\n", i, edge.Source.ID, edge.Destination.ID, value.id, value.num)
+			}
+		}
+	}
+
+	ew.printf("Nodes: %v, Unique edges: %v, total edges: %v\n", len(g.Nodes), len(edges), edgeCounter)
+	return ew.err
+}
+
+func formatTree(w io.Writer, g *Graph) error {
+	ew := &errWriter{w: w}
+	written := make(map[*Node]bool)
+
+	var visit func(n *Node, depth int)
+	visit = func(n *Node, depth int) {
+		indent := strings.Repeat("  ", depth)
+		if written[n] {
+			ew.printf("%v[%v] %v (*)\n", indent, n, g.label(n))
+			return
+		}
+
+		written[n] = true
+		ew.printf("%v[%v] %v\n", indent, n, g.label(n))
+		for _, e := range n.Edges {
+			if e.Source == n {
+				visit(e.Destination, depth+1)
+			}
+		}
+	}
+
+	nodes := g.sortedNodes()
+	for _, n := range nodes {
+		hasDependents := false
+		for _, e := range n.Edges {
+			if e.Destination == n && e.Source != n {
+				hasDependents = true
+				break
+			}
+		}
+
+		if !hasDependents {
+			visit(n, 0)
+		}
+	}
+
+	for _, n := range nodes {
+		if !written[n] {
+			visit(n, 0)
+		}
+	}
+
+	return ew.err
+}
+
+func formatTable(w io.Writer, g *Graph) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	ew := &errWriter{w: tw}
+	ew.printf("ID\tNODE\tTYPE\tREGION\tOUT\tIN\n")
+	for _, n := range g.sortedNodes() {
+		out, in := 0, 0
+		for _, e := range n.Edges {
+			if e.Source == n {
+				out++
+			}
+			if e.Destination == n {
+				in++
+			}
+		}
+
+		region := ""
+		if n.Region != nil {
+			region = fmt.Sprint(n.Region)
+		}
+
+		ew.printf("%v\t%v\t%T\t%v\t%v\t%v\n", n.ID, g.label(n), n.Data, region, out, in)
+	}
+
+	if ew.err != nil {
+		return ew.err
+	}
+
+	return tw.Flush()
+}
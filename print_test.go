@@ -0,0 +1,96 @@
+package graph
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGraph_fprint(t *testing.T) {
+	g := NewGraph()
+
+	n1 := g.NewNode("a")
+	n2 := g.NewNode("b")
+	n1.DependOn(n2)
+
+	var buf bytes.Buffer
+	if err := g.Fprint(&buf, EdgeFormatter); err != nil {
+		t.Fatalf("Unable to print graph: %v", err)
+	}
+
+	expected := "[Node-0] a -(<nil>)> [Node-1] b\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := g.Fprint(&buf, TableFormatter); err != nil {
+		t.Fatalf("Unable to print graph: %v", err)
+	}
+
+	expected = "ID  NODE  TYPE    REGION  OUT  IN\n" +
+		"0   a     string          1    0\n" +
+		"1   b     string          0    1\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestGraph_fprintFormatters(t *testing.T) {
+	g := NewGraph()
+
+	a := g.NewNode("a")
+	b := g.NewNode("b")
+	c := g.NewNode("c")
+	d := g.NewNode("d")
+	a.DependOn(b)
+	a.DependOn(c)
+	b.DependOn(c)
+	d.DependOn(g.NewNode("e"))
+	g.Find("e").DependOn(d)
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		expected  string
+	}{
+		{"nodes", NodeFormatter, "[Node-0]: a(string)\n" +
+			"[Node-1]: b(string)\n" +
+			"[Node-2]: c(string)\n" +
+			"[Node-3]: d(string)\n" +
+			"[Node-4]: e(string)\n"},
+		{"details", DetailFormatter, "[Node-0]: a(string)\n" +
+			"  [0] 0 -> 1 [0/1]\n" +
+			"  [1] 0 -> 2 [1/1]\n" +
+			"[Node-1]: b(string)\n" +
+			"  [0] 1 <- 0 [0/2]\n" +
+			"  [1] 1 -> 2 [2/1]\n" +
+			"[Node-2]: c(string)\n" +
+			"  [0] 2 <- 0 [1/2]\n" +
+			"  [1] 2 <- 1 [2/2]\n" +
+			"[Node-3]: d(string)\n" +
+			"  [0] 3 -> 4 [3/1]\n" +
+			"  [1] 3 <- 4 [4/1]\n" +
+			"[Node-4]: e(string)\n" +
+			"  [0] 4 <- 3 [3/2]\n" +
+			"  [1] 4 -> 3 [4/2]\n" +
+			"Nodes: 5, Unique edges: 5, total edges: 10\n"},
+		{"tree", TreeFormatter, "[Node-0] a\n" +
+			"  [Node-1] b\n" +
+			"    [Node-2] c\n" +
+			"  [Node-2] c (*)\n" +
+			"[Node-3] d\n" +
+			"  [Node-4] e\n" +
+			"    [Node-3] d (*)\n"},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := g.Fprint(&buf, test.formatter); err != nil {
+			t.Fatalf("Unable to print graph: %v", err)
+		}
+
+		if buf.String() != test.expected {
+			t.Errorf("%v: expected %q, got %q", test.name, test.expected, buf.String())
+		}
+	}
+}
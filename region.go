@@ -0,0 +1,310 @@
+package graph
+
+import "fmt"
+
+// RegionEdge is the data of an edge in a region graph.
+type RegionEdge struct {
+	// Count is the number of edges between the two regions
+	Count int
+
+	// Edges are the underlying edges between members of the two regions
+	Edges []*Edge
+}
+
+// RegionGraph returns a new graph whose nodes are the regions of this graph.
+// A region depends on another region if any of its members depends on a member
+// of the other region. The data of each region edge is a *RegionEdge.
+// Nodes without a region are left out.
+func (g *Graph) RegionGraph() *Graph {
+	return g.regionGraph(func(*Edge) bool {
+		return true
+	})
+}
+
+// regionGraph returns the region graph of the edges satisfying follow
+func (g *Graph) regionGraph(follow func(*Edge) bool) *Graph {
+	rg := NewGraph()
+	nodes := g.sortedNodes()
+
+	for _, n := range nodes {
+		if n.Region != nil {
+			rg.NewNode(n.Region)
+		}
+	}
+
+	for _, n := range nodes {
+		for _, e := range n.Edges {
+			if e.Source != n || e.Source.Region == nil || e.Destination.Region == nil {
+				continue
+			}
+
+			if !e.CrossRegion || !follow(e) {
+				continue
+			}
+
+			edge := rg.Find(e.Source.Region).DependOn(rg.Find(e.Destination.Region))
+			data, _ := edge.Data.(*RegionEdge)
+			if data == nil {
+				data = &RegionEdge{}
+				edge.Data = data
+			}
+
+			data.Count++
+			data.Edges = append(data.Edges, e)
+		}
+	}
+
+	return rg
+}
+
+// countRegionEdge adds delta to the number of ordering edges between the regions of the edge,
+// which the eager region cycle check searches instead of the whole graph
+func (g *Graph) countRegionEdge(e *Edge, delta int) {
+	if !e.CrossRegion || !e.Kind.Orders() || e.Source.Region == nil || e.Destination.Region == nil {
+		return
+	}
+
+	if g.regionEdges == nil {
+		g.regionEdges = make(map[interface{}]map[interface{}]int)
+	}
+
+	from, to := e.Source.Region, e.Destination.Region
+	targets := g.regionEdges[from]
+	if targets == nil {
+		targets = make(map[interface{}]int)
+		g.regionEdges[from] = targets
+	}
+
+	targets[to] += delta
+	if targets[to] <= 0 {
+		delete(targets, to)
+		if len(targets) == 0 {
+			delete(g.regionEdges, from)
+		}
+	}
+}
+
+// regionPath returns the regions on a shortest path of ordering edges from members of one region
+// to members of the other region, both included, or nil if there is none
+func (g *Graph) regionPath(from, to interface{}) []interface{} {
+	parents := map[interface{}]interface{}{from: nil}
+	queue := []interface{}{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for next := range g.regionEdges[current] {
+			if _, visited := parents[next]; visited {
+				continue
+			}
+
+			parents[next] = current
+			if next == to {
+				path := []interface{}{to}
+				for p := current; p != nil; p = parents[p] {
+					path = append([]interface{}{p}, path...)
+				}
+				return path
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return nil
+}
+
+// CrossRegionEdges returns all edges between nodes in different regions
+func (g *Graph) CrossRegionEdges() (edges []*Edge) {
+	for _, n := range g.sortedNodes() {
+		for _, e := range n.Edges {
+			if e.Source == n && e.CrossRegion {
+				edges = append(edges, e)
+			}
+		}
+	}
+
+	return
+}
+
+// RegionOutbound returns the edges from members of the region to nodes outside of it
+func (g *Graph) RegionOutbound(region interface{}) (edges []*Edge) {
+	for _, n := range g.Regions[region] {
+		for _, e := range n.Edges {
+			if e.Source == n && e.CrossRegion {
+				edges = append(edges, e)
+			}
+		}
+	}
+
+	return
+}
+
+// RegionInbound returns the edges from nodes outside of the region to members of it
+func (g *Graph) RegionInbound(region interface{}) (edges []*Edge) {
+	for _, n := range g.Regions[region] {
+		for _, e := range n.Edges {
+			if e.Destination == n && e.CrossRegion {
+				edges = append(edges, e)
+			}
+		}
+	}
+
+	return
+}
+
+// removeRegionMember removes the node from the member list of the region.
+// Empty regions are deleted.
+func (g *Graph) removeRegionMember(region interface{}, n *Node) {
+	members := g.Regions[region]
+	for i, m := range members {
+		if m == n {
+			members = append(members[:i], members[i+1:]...)
+			break
+		}
+	}
+
+	if len(members) == 0 {
+		delete(g.Regions, region)
+	} else {
+		g.Regions[region] = members
+	}
+}
+
+// DeleteRegion removes all members from the region and deletes it.
+// Child regions are moved to the parent of the deleted region.
+func (g *Graph) DeleteRegion(region interface{}) {
+	members := append([]*Node(nil), g.Regions[region]...)
+	for _, n := range members {
+		n.RemoveFromRegion()
+	}
+
+	delete(g.Regions, region)
+
+	parent := g.RegionParent(region)
+	for _, child := range g.RegionChildren(region) {
+		g.setRegionParent(child, parent)
+	}
+	delete(g.RegionParents, region)
+}
+
+// RenameRegion moves all members of a region into another region.
+// If the other region already has members, the two regions are merged.
+// A region cannot be renamed into a region nested within it, nor into the nil region.
+func (g *Graph) RenameRegion(from, to interface{}) error {
+	if from == to {
+		return nil
+	}
+
+	if to == nil {
+		return fmt.Errorf("region %v cannot be renamed into the nil region", from)
+	}
+
+	if g.IsRegionWithin(to, from) {
+		return fmt.Errorf("region %v cannot be renamed into %v nested within it", from, to)
+	}
+
+	members := append([]*Node(nil), g.Regions[from]...)
+	for _, n := range members {
+		n.PutIntoRegion(to)
+	}
+
+	if parent, hasParent := g.RegionParents[from]; hasParent {
+		// The parent is kept, unless the region is renamed into one of its ancestors
+		if _, hasParent := g.RegionParents[to]; !hasParent && !g.IsRegionWithin(parent, to) {
+			g.RegionParents[to] = parent
+		}
+		delete(g.RegionParents, from)
+	}
+
+	for _, child := range g.RegionChildren(from) {
+		g.setRegionParent(child, to)
+	}
+
+	return nil
+}
+
+// SetRegionParent nests a region within a parent region.
+// A nil parent makes the region a top level region.
+func (g *Graph) SetRegionParent(region, parent interface{}) error {
+	if region == nil {
+		return fmt.Errorf("the nil region cannot be nested")
+	}
+
+	if parent != nil && g.IsRegionWithin(parent, region) {
+		return fmt.Errorf("region %v cannot be nested within %v", region, parent)
+	}
+
+	g.setRegionParent(region, parent)
+	return nil
+}
+
+func (g *Graph) setRegionParent(region, parent interface{}) {
+	if parent == nil {
+		delete(g.RegionParents, region)
+	} else {
+		g.RegionParents[region] = parent
+	}
+}
+
+// RegionParent returns the parent of a region, or nil for top level regions
+func (g *Graph) RegionParent(region interface{}) interface{} {
+	return g.RegionParents[region]
+}
+
+// RegionChildren returns the regions directly nested within the region
+func (g *Graph) RegionChildren(region interface{}) (children []interface{}) {
+	for child, parent := range g.RegionParents {
+		if parent == region {
+			children = append(children, child)
+		}
+	}
+
+	return
+}
+
+// RegionAncestors returns the ancestors of a region, starting with its parent
+func (g *Graph) RegionAncestors(region interface{}) (ancestors []interface{}) {
+	for parent := g.RegionParent(region); parent != nil; parent = g.RegionParent(parent) {
+		ancestors = append(ancestors, parent)
+	}
+
+	return
+}
+
+// IsRegionWithin returns true if the region is the ancestor region or nested within it at any level
+func (g *Graph) IsRegionWithin(region, ancestor interface{}) bool {
+	for r := region; r != nil; r = g.RegionParent(r) {
+		if r == ancestor {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RegionDepth returns the level of a region in the hierarchy, zero for top level regions
+func (g *Graph) RegionDepth(region interface{}) int {
+	return len(g.RegionAncestors(region))
+}
+
+// RegionAtLevel returns the ancestor of the region at the given level of the hierarchy,
+// where top level regions are at level zero.
+// A region at or above the level is returned as is.
+func (g *Graph) RegionAtLevel(region interface{}, level int) interface{} {
+	ancestors := g.RegionAncestors(region)
+	if level >= len(ancestors) {
+		return region
+	}
+
+	return ancestors[len(ancestors)-1-level]
+}
+
+// NodesUnder returns the members of the region and of all regions nested within it
+func (g *Graph) NodesUnder(region interface{}) (nodes []*Node) {
+	for _, n := range g.sortedNodes() {
+		if n.Region != nil && g.IsRegionWithin(n.Region, region) {
+			nodes = append(nodes, n)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,228 @@
+package graph
+
+import "testing"
+
+func TestGraph_regionGraph(t *testing.T) {
+	g := NewGraph()
+
+	n1 := g.NewNode(1).PutIntoRegion("ui")
+	n2 := g.NewNode(2).PutIntoRegion("ui")
+	n3 := g.NewNode(3).PutIntoRegion("service")
+	n4 := g.NewNode(4).PutIntoRegion("storage")
+
+	n1.DependOn(n2)
+	n1.DependOn(n3)
+	n2.DependOn(n3)
+	n3.DependOn(n4)
+
+	rg := g.RegionGraph()
+	if rg.Size() != 3 {
+		t.Fatalf("Expected 3 regions, got %v", rg.Size())
+	}
+
+	if rg.NumberOfEdges() != 2 {
+		t.Errorf("Expected 2 region edges, got %v", rg.NumberOfEdges())
+	}
+
+	e := rg.Find("ui").DependsOnAdjacent(rg.Find("service"))
+	if e == nil {
+		t.Fatalf("ui should depend on service")
+	}
+
+	data := e.Data.(*RegionEdge)
+	if data.Count != 2 || len(data.Edges) != 2 {
+		t.Errorf("Expected 2 underlying edges, got %v", data.Count)
+	}
+}
+
+func TestGraph_crossRegion(t *testing.T) {
+	g := NewGraph()
+
+	n1 := g.NewNode(1).PutIntoRegion(1)
+	n2 := g.NewNode(2).PutIntoRegion(1)
+	n3 := g.NewNode(3)
+
+	e1 := n1.DependOn(n2)
+	e2 := n2.DependOn(n3)
+
+	if e1.CrossRegion || !e2.CrossRegion {
+		t.Errorf("The CrossRegion flags were not set on creation")
+	}
+
+	n3.PutIntoRegion(1)
+	if e2.CrossRegion {
+		t.Errorf("The CrossRegion flag was not updated")
+	}
+
+	n1.PutIntoRegion(2)
+	if !e1.CrossRegion {
+		t.Errorf("The CrossRegion flag was not updated")
+	}
+
+	if len(g.CrossRegionEdges()) != 1 {
+		t.Errorf("Expected 1 cross region edge, got %v", len(g.CrossRegionEdges()))
+	}
+
+	if len(g.RegionOutbound(2)) != 1 || len(g.RegionInbound(2)) != 0 {
+		t.Errorf("Region 2 should have one outbound and no inbound edges")
+	}
+}
+
+func TestGraph_regionMembership(t *testing.T) {
+	g := NewGraph()
+
+	changes := 0
+	g.OnRegionChanged = func(node *Node, from, to interface{}) {
+		changes++
+	}
+
+	n1 := g.NewNode(1).PutIntoRegion(1)
+	n2 := g.NewNode(2).PutIntoRegion(1)
+	n1.PutIntoRegion(1)
+
+	if len(g.Regions[1]) != 2 {
+		t.Errorf("Expected 2 members of region 1, got %v", len(g.Regions[1]))
+	}
+
+	n1.PutIntoRegion(2)
+	if len(g.Regions[1]) != 1 || len(g.Regions[2]) != 1 {
+		t.Errorf("The node was not moved from region 1 to region 2")
+	}
+
+	g.RenameRegion(2, 3)
+	if _, hasRegion := g.Regions[2]; hasRegion || n1.Region != 3 {
+		t.Errorf("Region 2 was not renamed")
+	}
+
+	n2.RemoveFromRegion()
+	if _, hasRegion := g.Regions[1]; hasRegion || n2.Region != nil {
+		t.Errorf("The node was not removed from region 1")
+	}
+
+	g.DeleteRegion(3)
+	if len(g.Regions) != 0 || n1.Region != nil {
+		t.Errorf("Region 3 was not deleted")
+	}
+
+	if changes != 6 {
+		t.Errorf("Expected 6 region changes, got %v", changes)
+	}
+}
+
+func TestGraph_nestedRegions(t *testing.T) {
+	g := NewGraph()
+
+	g.SetRegionParent("service", "repo")
+	g.SetRegionParent("pkg1", "service")
+	g.SetRegionParent("pkg2", "service")
+
+	if err := g.SetRegionParent("repo", "pkg1"); err == nil {
+		t.Errorf("Nesting a region within its descendant should fail")
+	}
+
+	n1 := g.NewNode(1).PutIntoRegion("pkg1")
+	n2 := g.NewNode(2).PutIntoRegion("pkg1")
+	n3 := g.NewNode(3).PutIntoRegion("pkg2")
+	n4 := g.NewNode(4).PutIntoRegion("repo")
+
+	e1 := n1.DependOn(n3)
+	e2 := n3.DependOn(n2)
+	n2.DependOn(n4)
+
+	if len(g.NodesUnder("service")) != 3 || len(g.NodesUnder("repo")) != 4 {
+		t.Errorf("Unexpected nodes under regions")
+	}
+
+	if g.RegionAtLevel("pkg1", 1) != "service" || g.RegionAtLevel("repo", 2) != "repo" {
+		t.Errorf("Unexpected regions at levels")
+	}
+
+	if !e1.CrossRegionAt(2) || e1.CrossRegionAt(1) {
+		t.Errorf("Unexpected cross region detection at levels")
+	}
+
+	sorted, err := NewRegionTopologicalSortAtLevel(1).Sort(g.NodesUnder("service"))
+	if err != nil {
+		t.Fatalf("Unable to sort topological: %v", err)
+	}
+
+	if len(sorted) != 3 {
+		t.Errorf("Expected 3 sorted nodes, got %v", len(sorted))
+	}
+
+	if findNode(sorted, n2) > findNode(sorted, n3) || findNode(sorted, n3) > findNode(sorted, n1) {
+		t.Errorf("Unexpected order %v", sorted)
+	}
+
+	visited := 0
+	n3.Walk(NewDepthFirstWalkerWithinRegionLevel(func(*Node, *Edge) {
+		visited++
+	}, 1))
+	if visited != 1 || !e2.CrossRegion {
+		t.Errorf("Expected to walk 1 edge, walked %v", visited)
+	}
+
+	g.DeleteRegion("service")
+	if g.RegionParent("pkg1") != "repo" {
+		t.Errorf("Child regions should be moved to the parent of the deleted region")
+	}
+}
+
+func TestGraph_renameRegionNested(t *testing.T) {
+	g := NewGraph()
+	g.SetRegionParent("pkg", "svc")
+	n := g.NewNode(1).PutIntoRegion("svc")
+
+	if err := g.RenameRegion("svc", "pkg"); err == nil {
+		t.Fatalf("Expected renaming a region into its child to fail")
+	}
+
+	if n.Region != "svc" || g.RegionParent("pkg") != "svc" || len(g.RegionAncestors("pkg")) != 1 {
+		t.Errorf("The failed rename should leave the regions unchanged")
+	}
+
+	if err := g.RenameRegion("pkg", "svc"); err != nil || g.RegionParent("svc") != nil {
+		t.Errorf("Expected renaming a child into its parent to merge them, got %v", err)
+	}
+}
+
+func TestGraph_analyzeRegions(t *testing.T) {
+	g := NewGraph()
+
+	n1 := g.NewNode(1).PutIntoRegion(1)
+	n2 := g.NewNode(2).PutIntoRegion(1)
+	n3 := g.NewNode(3).PutIntoRegion(2)
+	n4 := g.NewNode(4).PutIntoRegion(2)
+	n5 := g.NewNode(5).PutIntoRegion(3)
+
+	n1.DependOn(n2)
+	n3.DependOn(n4)
+	n4.DependOn(n3)
+	n2.DependOn(n5)
+	n5.DependOn(n1)
+
+	report := g.AnalyzeRegions()
+	if len(report.Regions) != 3 {
+		t.Fatalf("Expected 3 regions, got %v", len(report.Regions))
+	}
+
+	r1 := report.Region(1)
+	if len(r1.Cycles) != 0 || len(r1.Order) != 2 || r1.Order[0] != n2 {
+		t.Errorf("Region 1 should have no cycles and be ordered, got %v", r1.Order)
+	}
+
+	r2 := report.Region(2)
+	if len(r2.Cycles) != 1 || r2.Order != nil {
+		t.Errorf("Region 2 should have one cycle")
+	}
+
+	if len(report.CrossRegionCycles) != 1 || len(report.CrossRegionCycles[0]) != 3 {
+		t.Errorf("Expected one cross region cycle, got %v", report.CrossRegionCycles)
+	}
+
+	// The report keeps its nodes when the regions change
+	n1.PutIntoRegion(2)
+	if len(r1.Nodes) != 2 || r1.Nodes[0] != n1 || r1.Nodes[1] != n2 {
+		t.Errorf("Expected the report to keep nodes 1 and 2, got %v", r1.Nodes)
+	}
+}
@@ -0,0 +1,104 @@
+package graph
+
+import "io"
+
+// RegionAnalysis holds the cycles and the topological order of a single region
+type RegionAnalysis struct {
+	Region interface{}
+
+	// Nodes are the members of the region
+	Nodes []*Node
+
+	// Cycles are the cyclic dependencies wholly inside the region, including self-loops
+	Cycles [][]*Node
+
+	// Order is the topological order of the region, dependencies first.
+	// It is nil if the region has cycles.
+	Order []*Node
+}
+
+// RegionReport is the per region analysis of a graph
+type RegionReport struct {
+	// Regions are ordered by the id of their first member
+	Regions []*RegionAnalysis
+
+	// CrossRegionCycles are the cyclic dependencies spanning several regions
+	CrossRegionCycles [][]*Node
+}
+
+// AnalyzeRegions reports, for every region, the cycles inside the region and
+// its topological order, and separately the cycles spanning several regions.
+func (g *Graph) AnalyzeRegions() *RegionReport {
+	report := &RegionReport{}
+
+	seen := make(map[interface{}]bool)
+	for _, n := range g.sortedNodes() {
+		if n.Region == nil || seen[n.Region] {
+			continue
+		}
+		seen[n.Region] = true
+
+		// The members are copied, as the region changes them in place
+		analysis := &RegionAnalysis{
+			Region: n.Region,
+			Nodes:  append([]*Node(nil), g.Regions[n.Region]...),
+		}
+
+		follow := func(node *Node, edge *Edge) bool {
+			return node == edge.Source && !edge.CrossRegion && edge.Kind.Orders()
+		}
+		for _, component := range stronglyConnectedComponents(analysis.Nodes, follow) {
+			if isCyclic(component, follow) {
+				analysis.Cycles = append(analysis.Cycles, component)
+			}
+		}
+
+		if len(analysis.Cycles) == 0 {
+			analysis.Order, _ = g.TopologicalSort(n.Region)
+		}
+
+		report.Regions = append(report.Regions, analysis)
+	}
+
+	for _, component := range g.Cycles() {
+		for _, n := range component[1:] {
+			if n.Region != component[0].Region {
+				report.CrossRegionCycles = append(report.CrossRegionCycles, component)
+				break
+			}
+		}
+	}
+
+	return report
+}
+
+// Region returns the analysis of the given region, or nil
+func (r *RegionReport) Region(region interface{}) *RegionAnalysis {
+	for _, analysis := range r.Regions {
+		if analysis.Region == region {
+			return analysis
+		}
+	}
+
+	return nil
+}
+
+// Fprint writes the report to w, one section per region
+func (r *RegionReport) Fprint(w io.Writer) error {
+	ew := &errWriter{w: w}
+	for _, analysis := range r.Regions {
+		ew.printf("region %v: %v nodes\n", analysis.Region, len(analysis.Nodes))
+		for _, cycle := range analysis.Cycles {
+			ew.printf("  cycle: %v\n", cycle)
+		}
+		if analysis.Order != nil {
+			ew.printf("  order: %v\n", analysis.Order)
+		}
+	}
+
+	for _, cycle := range r.CrossRegionCycles {
+		ew.printf("cross region cycle: %v\n", cycle)
+	}
+
+	return ew.err
+}
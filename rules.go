@@ -0,0 +1,231 @@
+package graph
+
+import "fmt"
+
+// RuleKind identifies the kind of rule that was violated
+type RuleKind uint8
+
+const (
+	// DenyRule is violated by a dependency on a denied region
+	DenyRule RuleKind = iota
+	// AllowRule is violated by a dependency on a region that is not allowed
+	AllowRule
+	// LayerRule is violated by a lower layer depending on a higher layer
+	LayerRule
+	// RegionCycleRule is violated by cyclic dependencies between regions
+	RegionCycleRule
+)
+
+// Violation describes dependencies breaking a rule
+type Violation struct {
+	Kind RuleKind
+
+	// From is the region of the dependent nodes
+	From interface{}
+
+	// To is the region of the dependencies
+	To interface{}
+
+	// Cycle holds the regions of a RegionCycleRule violation
+	Cycle []interface{}
+
+	// Edges are the offending edges
+	Edges []*Edge
+}
+
+func (v Violation) Error() string {
+	switch v.Kind {
+	case AllowRule:
+		return fmt.Sprintf("region %v is not allowed to depend on %v (%v edges)", v.From, v.To, len(v.Edges))
+	case LayerRule:
+		return fmt.Sprintf("region %v must not depend on the higher layer %v (%v edges)", v.From, v.To, len(v.Edges))
+	case RegionCycleRule:
+		return fmt.Sprintf("regions %v depend cyclically on each other (%v edges)", v.Cycle, len(v.Edges))
+	default:
+		return fmt.Sprintf("region %v must not depend on %v (%v edges)", v.From, v.To, len(v.Edges))
+	}
+}
+
+// RuleSet describes which regions may depend on each other.
+// Rules given for a region apply to all regions nested within it, and
+// dependencies within a region are never restricted by its own rules.
+type RuleSet struct {
+	// Enforce makes DependOn refuse edges violating the rules, returning nil
+	Enforce bool
+
+	// NoRegionCycles forbids cyclic dependencies between regions. Soft edges impose no order and are ignored.
+	NoRegionCycles bool
+
+	allow  map[interface{}][]interface{}
+	deny   map[interface{}][]interface{}
+	layers []interface{}
+}
+
+// NewRuleSet returns an empty rule set
+func NewRuleSet() *RuleSet {
+	return &RuleSet{
+		allow: make(map[interface{}][]interface{}),
+		deny:  make(map[interface{}][]interface{}),
+	}
+}
+
+// Allow adds regions the region may depend on.
+// Once a region has allowed regions, dependencies on any other region are violations.
+func (rs *RuleSet) Allow(region interface{}, regions ...interface{}) *RuleSet {
+	rs.allow[region] = append(rs.allow[region], regions...)
+	return rs
+}
+
+// Deny adds regions the region must not depend on
+func (rs *RuleSet) Deny(region interface{}, regions ...interface{}) *RuleSet {
+	rs.deny[region] = append(rs.deny[region], regions...)
+	return rs
+}
+
+// Layers orders regions from the top layer to the bottom layer.
+// A layer may only depend on itself and the layers below it.
+func (rs *RuleSet) Layers(layers ...interface{}) *RuleSet {
+	rs.layers = layers
+	return rs
+}
+
+func (rs *RuleSet) layer(g *Graph, region interface{}) int {
+	for i, l := range rs.layers {
+		if g.IsRegionWithin(region, l) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// checkEdge returns the kinds of the allow, deny and layer rules violated by a cross region edge
+func (rs *RuleSet) checkEdge(g *Graph, e *Edge) (kinds []RuleKind) {
+	from, to := e.Source.Region, e.Destination.Region
+
+	for region, denied := range rs.deny {
+		if !g.IsRegionWithin(from, region) || g.IsRegionWithin(to, region) {
+			continue
+		}
+
+		for _, d := range denied {
+			if g.IsRegionWithin(to, d) {
+				kinds = append(kinds, DenyRule)
+				break
+			}
+		}
+	}
+
+	for region, allowed := range rs.allow {
+		if !g.IsRegionWithin(from, region) || g.IsRegionWithin(to, region) {
+			continue
+		}
+
+		isAllowed := false
+		for _, a := range allowed {
+			if g.IsRegionWithin(to, a) {
+				isAllowed = true
+				break
+			}
+		}
+
+		if !isAllowed {
+			kinds = append(kinds, AllowRule)
+		}
+	}
+
+	lf, lt := rs.layer(g, from), rs.layer(g, to)
+	if lf >= 0 && lt >= 0 && lf > lt {
+		kinds = append(kinds, LayerRule)
+	}
+
+	return
+}
+
+// checkNewEdge returns the violations an edge would introduce
+func (rs *RuleSet) checkNewEdge(g *Graph, e *Edge) (violations []Violation) {
+	if !e.CrossRegion {
+		return
+	}
+
+	for _, kind := range rs.checkEdge(g, e) {
+		violations = append(violations, Violation{Kind: kind, From: e.Source.Region, To: e.Destination.Region, Edges: []*Edge{e}})
+	}
+
+	if rs.NoRegionCycles && e.Kind.Orders() && e.Source.Region != nil && e.Destination.Region != nil {
+		// The cycle starts at the region of the dependent, each region depending on the next
+		if path := g.regionPath(e.Destination.Region, e.Source.Region); path != nil {
+			violations = append(violations, Violation{
+				Kind:  RegionCycleRule,
+				From:  e.Source.Region,
+				To:    e.Destination.Region,
+				Cycle: append([]interface{}{e.Source.Region}, path[:len(path)-1]...),
+				Edges: []*Edge{e},
+			})
+		}
+	}
+
+	return
+}
+
+// Check returns all violations of the rules in the graph.
+// Edges breaking the same rule between the same two regions are reported as one violation.
+func (rs *RuleSet) Check(g *Graph) (violations []Violation) {
+	type violationKey struct {
+		kind     RuleKind
+		from, to interface{}
+	}
+
+	index := make(map[violationKey]int)
+	for _, e := range g.CrossRegionEdges() {
+		for _, kind := range rs.checkEdge(g, e) {
+			key := violationKey{kind, e.Source.Region, e.Destination.Region}
+			i, hasViolation := index[key]
+			if !hasViolation {
+				i = len(violations)
+				index[key] = i
+				violations = append(violations, Violation{Kind: kind, From: key.from, To: key.to})
+			}
+			violations[i].Edges = append(violations[i].Edges, e)
+		}
+	}
+
+	if rs.NoRegionCycles {
+		// Soft edges impose no order, as for Cycles
+		rg := g.regionGraph(func(e *Edge) bool {
+			return e.Kind.Orders()
+		})
+		for _, component := range rg.StronglyConnectedComponents() {
+			if len(component) < 2 {
+				continue
+			}
+
+			v := Violation{Kind: RegionCycleRule}
+			members := make(map[*Node]bool)
+			for _, n := range component {
+				members[n] = true
+				v.Cycle = append(v.Cycle, n.Data)
+			}
+
+			for _, n := range component {
+				for _, e := range n.Edges {
+					if e.Source == n && members[e.Destination] {
+						v.Edges = append(v.Edges, e.Data.(*RegionEdge).Edges...)
+					}
+				}
+			}
+			violations = append(violations, v)
+		}
+	}
+
+	return
+}
+
+// CheckRules returns all violations of the rules attached to the graph
+func (g *Graph) CheckRules() []Violation {
+	if g.Rules == nil {
+		return nil
+	}
+
+	return g.Rules.Check(g)
+}
@@ -0,0 +1,141 @@
+package graph
+
+import "testing"
+
+func TestGraph_rulesBatch(t *testing.T) {
+	g := NewGraph()
+
+	ui := g.NewNode(1).PutIntoRegion("ui")
+	service := g.NewNode(2).PutIntoRegion("service")
+	storage := g.NewNode(3).PutIntoRegion("storage")
+
+	ui.DependOn(service)
+	ui.DependOn(storage)
+	storage.DependOn(service)
+	service.DependOn(storage)
+
+	g.Rules = NewRuleSet().Deny("ui", "storage").Layers("ui", "service", "storage")
+	g.Rules.NoRegionCycles = true
+
+	violations := g.CheckRules()
+	kinds := make(map[RuleKind]int)
+	for _, v := range violations {
+		kinds[v.Kind]++
+	}
+
+	if kinds[DenyRule] != 1 || kinds[LayerRule] != 1 || kinds[RegionCycleRule] != 1 {
+		t.Errorf("Unexpected violations %v", violations)
+	}
+}
+
+func TestGraph_rulesEnforced(t *testing.T) {
+	g := NewGraph()
+
+	reported := 0
+	g.OnRuleViolation = func(v Violation) {
+		reported++
+	}
+	g.Rules = NewRuleSet().Allow("ui", "service")
+	g.Rules.Enforce = true
+
+	g.SetRegionParent("widgets", "ui")
+	widget := g.NewNode(1).PutIntoRegion("widgets")
+	ui := g.NewNode(2).PutIntoRegion("ui")
+	service := g.NewNode(3).PutIntoRegion("service")
+	storage := g.NewNode(4).PutIntoRegion("storage")
+
+	if widget.DependOn(ui) == nil {
+		t.Errorf("Dependencies within a region should be allowed")
+	}
+
+	if widget.DependOn(service) == nil {
+		t.Errorf("Dependencies on allowed regions should be allowed")
+	}
+
+	if widget.DependOn(storage) != nil {
+		t.Errorf("Dependencies on regions not allowed should be refused")
+	}
+
+	if reported != 1 {
+		t.Errorf("Expected 1 reported violation, got %v", reported)
+	}
+}
+
+func TestGraph_rulesRegionCycles(t *testing.T) {
+	g := NewGraph()
+	g.Rules = NewRuleSet()
+	g.Rules.NoRegionCycles = true
+	g.Rules.Enforce = true
+
+	// A long chain across regions must be checked without rebuilding the region graph
+	const length, regions = 8000, 50
+	previous := g.NewNode(0).PutIntoRegion(0)
+	for i := 1; i <= length; i++ {
+		n := g.NewNode(i).PutIntoRegion(i * regions / (length + 1))
+		if previous.DependOn(n) == nil {
+			t.Fatalf("Unexpected refusal of the edge from %v to %v", previous, n)
+		}
+		previous = n
+	}
+
+	first, last := g.Find(0), g.Find(length)
+	if last.DependOn(first) != nil {
+		t.Errorf("Expected the edge closing a region cycle to be refused")
+	}
+
+	if last.DependOnKind(first, SoftEdge) == nil || len(g.CheckRules()) != 0 {
+		t.Errorf("Soft edges should not close region cycles")
+	}
+
+	// The region edges follow the nodes when they move
+	a := g.NewNode("a").PutIntoRegion("r1")
+	b := g.NewNode("b").PutIntoRegion("r2")
+	a.DependOn(b)
+	b.PutIntoRegion("r3")
+	if g.NewNode("c").PutIntoRegion("r2").DependOn(a) == nil {
+		t.Errorf("Expected the edge to be allowed after the move")
+	}
+	if g.NewNode("d").PutIntoRegion("r3").DependOn(a) != nil {
+		t.Errorf("Expected the edge closing a region cycle to be refused after the move")
+	}
+
+	// The violation holds every region of the cycle
+	var cycle []interface{}
+	g.OnRuleViolation = func(v Violation) {
+		cycle = v.Cycle
+	}
+	x := g.NewNode("x").PutIntoRegion("A")
+	y := g.NewNode("y").PutIntoRegion("B")
+	z := g.NewNode("z").PutIntoRegion("C")
+	x.DependOn(y)
+	y.DependOn(z)
+	if z.DependOn(x) != nil {
+		t.Errorf("Expected the edge closing a region cycle to be refused")
+	}
+	if len(cycle) != 3 || cycle[0] != "C" || cycle[1] != "A" || cycle[2] != "B" {
+		t.Errorf("Expected the cycle [C A B], got %v", cycle)
+	}
+}
+
+func TestGraph_stronglyConnectedComponents(t *testing.T) {
+	g := NewGraph()
+
+	n1 := g.NewNode(1)
+	n2 := g.NewNode(2)
+	n3 := g.NewNode(3)
+	n4 := g.NewNode(4)
+
+	n1.DependOn(n2)
+	n2.DependOn(n3)
+	n3.DependOn(n2)
+	n3.DependOn(n4)
+
+	components := g.StronglyConnectedComponents()
+	if len(components) != 3 {
+		t.Fatalf("Expected 3 components, got %v", len(components))
+	}
+
+	if len(components[0]) != 1 || components[0][0] != n4 || len(components[1]) != 2 || components[2][0] != n1 {
+		t.Errorf("Unexpected components %v", components)
+	}
+}
@@ -0,0 +1,138 @@
+package graph
+
+// StronglyConnectedComponents returns the strongly connected components of the graph.
+// Each node belongs to exactly one component. Components are ordered such that
+// dependencies come before their dependents. Soft edges are ignored.
+// In undirected mode, the 2-edge-connected components are returned in the order of their first node,
+// such that two nodes share a component if they lie on a common cycle.
+func (g *Graph) StronglyConnectedComponents() [][]*Node {
+	if g.Undirected {
+		components, _ := g.undirectedComponents()
+		return components
+	}
+
+	return stronglyConnectedComponents(g.sortedNodes(), func(node *Node, edge *Edge) bool {
+		return node == edge.Source && edge.Kind.Orders()
+	})
+}
+
+// Cycles returns the cyclic dependencies of the graph, each being a strongly
+// connected component of several nodes, or a single node with a self-loop.
+// Soft edges are ignored. In undirected mode, the cycles are the 2-edge-connected components
+// having an edge, which leaves out the nodes only attached by bridges.
+func (g *Graph) Cycles() (cycles [][]*Node) {
+	if g.Undirected {
+		components, cyclic := g.undirectedComponents()
+		for i, component := range components {
+			if cyclic[i] {
+				cycles = append(cycles, component)
+			}
+		}
+		return
+	}
+
+	follow := func(node *Node, edge *Edge) bool {
+		return node == edge.Source && edge.Kind.Orders()
+	}
+
+	for _, component := range stronglyConnectedComponents(g.sortedNodes(), follow) {
+		if isCyclic(component, follow) {
+			cycles = append(cycles, component)
+		}
+	}
+
+	return
+}
+
+// isCyclic returns true if the component has more than one node or a followed self-loop
+func isCyclic(component []*Node, follow func(*Node, *Edge) bool) bool {
+	if len(component) > 1 {
+		return true
+	}
+
+	for _, e := range component[0].Edges {
+		if e.Destination == component[0] && follow(component[0], e) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stronglyConnectedComponents finds the components reachable from the given nodes
+// using an iterative version of Tarjan's algorithm.
+// Only the edges satisfying follow are traversed, from the node to the edge destination.
+func stronglyConnectedComponents(nodes []*Node, follow func(*Node, *Edge) bool) (components [][]*Node) {
+	index := make(map[*Node]int)
+	lowlink := make(map[*Node]int)
+	onStack := make(map[*Node]bool)
+	var stack []*Node
+
+	type frame struct {
+		node *Node
+		edge int
+	}
+
+	visit := func(n *Node) {
+		index[n] = len(index)
+		lowlink[n] = index[n]
+		stack = append(stack, n)
+		onStack[n] = true
+	}
+
+	for _, root := range nodes {
+		if _, visited := index[root]; visited {
+			continue
+		}
+
+		visit(root)
+		calls := []frame{{root, 0}}
+		for len(calls) > 0 {
+			f := &calls[len(calls)-1]
+			n := f.node
+
+			if f.edge < len(n.Edges) {
+				e := n.Edges[f.edge]
+				f.edge++
+				if !follow(n, e) {
+					continue
+				}
+
+				m := e.Destination
+				if _, visited := index[m]; !visited {
+					visit(m)
+					calls = append(calls, frame{m, 0})
+				} else if onStack[m] && index[m] < lowlink[n] {
+					lowlink[n] = index[m]
+				}
+				continue
+			}
+
+			calls = calls[:len(calls)-1]
+			if len(calls) > 0 {
+				parent := calls[len(calls)-1].node
+				if lowlink[n] < lowlink[parent] {
+					lowlink[parent] = lowlink[n]
+				}
+			}
+
+			if lowlink[n] != index[n] {
+				continue
+			}
+
+			var component []*Node
+			for {
+				m := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[m] = false
+				component = append(component, m)
+				if m == n {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	return
+}
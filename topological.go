@@ -16,34 +16,67 @@ type TopologicalSort struct {
 	edgeCriteria func(*Node, *Edge) bool
 }
 
+// NewTopologicalSort returns a sort ordering dependencies before their dependents.
+// Soft edges impose no order. Edges between different regions are followed as well,
+// unlike in earlier versions, use NewRegionTopologicalSort to ignore them.
 func NewTopologicalSort() *TopologicalSort {
 	return &TopologicalSort{
 		edgeCriteria: func(node *Node, edge *Edge) bool {
-			// Exclude edges where n is the dependency
-			// Or, find the edges where n points to the destination
-			if node == edge.Source {
+			// Exclude edges where n is the dependent
+			// Or, find the edges pointing to n, including self-loops
+			if node != edge.Destination {
 				return false
 			}
-			// If doing a regional search, exclude those edges where
-			// regions do not match.
-			if node.localSort && node.Region != edge.Source.Region {
+			return edge.Kind.Orders()
+		},
+	}
+}
+
+// NewRegionTopologicalSort returns a sort ignoring edges between different regions
+func NewRegionTopologicalSort() *TopologicalSort {
+	return &TopologicalSort{
+		edgeCriteria: func(node *Node, edge *Edge) bool {
+			if node != edge.Destination {
 				return false
 			}
-			return true
+			// Exclude those edges where regions do not match.
+			return node.Region == edge.Source.Region && edge.Kind.Orders()
 		},
 	}
 }
 
+// NewRegionTopologicalSortAtLevel returns a sort ignoring edges between
+// different regions at the given level of the region hierarchy.
+func NewRegionTopologicalSortAtLevel(level int) *TopologicalSort {
+	return NewCustomTopologicalSort(func(node *Node, edge *Edge) bool {
+		if node != edge.Destination {
+			return false
+		}
+
+		return !edge.CrossRegionAt(level) && edge.Kind.Orders()
+	})
+}
+
 func NewCustomTopologicalSort(edgeCriteria func(*Node, *Edge) bool) *TopologicalSort {
 	return &TopologicalSort{
 		edgeCriteria: edgeCriteria,
 	}
 }
 
+// OnlyKinds restricts the sort to edges of the given kinds, in addition to its own criteria
+func (ts *TopologicalSort) OnlyKinds(kinds ...EdgeKind) *TopologicalSort {
+	criteria := ts.edgeCriteria
+	ts.edgeCriteria = func(node *Node, edge *Edge) bool {
+		return edge.Kind.hasKind(kinds) && criteria(node, edge)
+	}
+	return ts
+}
+
+// Sort returns the nodes such that dependencies come before their dependents.
+// It fails with "Not a DAG" if the followed edges form a cycle, wherever it is found.
 func (ts *TopologicalSort) Sort(nodes []*Node) (sorted []*Node, err error) {
 	for _, n := range nodes {
 		n.mark = unmarked
-		n.localSort = true
 	}
 
 	for {
@@ -54,10 +87,15 @@ func (ts *TopologicalSort) Sort(nodes []*Node) (sorted []*Node, err error) {
 
 		err = ts.topologicalSortVisit(unmarked, &sorted)
 		if err != nil {
-			return
+			return nil, err
 		}
 	}
 
+	// The nodes were appended dependents first
+	for i, j := 0, len(sorted)-1; i < j; i, j = i+1, j-1 {
+		sorted[i], sorted[j] = sorted[j], sorted[i]
+	}
+
 	return
 }
 
@@ -75,11 +113,13 @@ func (ts *TopologicalSort) topologicalSortVisit(n *Node, sorted *[]*Node) error
 				continue
 			}
 
-			ts.topologicalSortVisit(edge.Source, sorted)
+			if err := ts.topologicalSortVisit(edge.Source, sorted); err != nil {
+				return err
+			}
 		}
 
 		n.mark = permanentlyMarked
-		*sorted = append([]*Node{n}, *sorted...)
+		*sorted = append(*sorted, n)
 	}
 
 	return nil
@@ -94,3 +134,23 @@ func (ts *TopologicalSort) findUnmarkedNode(nodes []*Node) *Node {
 
 	return nil
 }
+
+// SortTopological sorts the nodes such that dependencies come before their dependents
+func SortTopological(nodes []*Node) ([]*Node, error) {
+	return NewTopologicalSort().Sort(nodes)
+}
+
+// TopologicalSort sorts the nodes of the graph such that dependencies come before their dependents.
+// If a region is given, only the members of the region are sorted, ignoring edges to other regions.
+// Undirected graphs cannot be sorted.
+func (g *Graph) TopologicalSort(region interface{}) ([]*Node, error) {
+	if g.Undirected {
+		return nil, fmt.Errorf("an undirected graph cannot be sorted topologically")
+	}
+
+	if region == nil {
+		return SortTopological(g.sortedNodes())
+	}
+
+	return NewRegionTopologicalSort().Sort(g.Regions[region])
+}
@@ -0,0 +1,108 @@
+package graph
+
+import "io"
+
+// TreeRenderer renders the dependency, or dependent, tree of a node with box-drawing characters.
+// Nodes that have already been rendered are marked instead of expanded again,
+// and so are nodes closing a cycle.
+type TreeRenderer struct {
+	// Direction decides whether dependencies or dependents are rendered
+	Direction Direction
+
+	// Label returns the text for a node, the graph's NodeStringer if nil
+	Label func(*Node) string
+
+	// MaxDepth limits the depth of the tree, unlimited if zero
+	MaxDepth int
+}
+
+// NewDependencyTreeRenderer returns a renderer following outbound edges
+func NewDependencyTreeRenderer() *TreeRenderer {
+	return &TreeRenderer{Direction: Outbound}
+}
+
+// NewDependentTreeRenderer returns a renderer following inbound edges
+func NewDependentTreeRenderer() *TreeRenderer {
+	return &TreeRenderer{Direction: Inbound}
+}
+
+const (
+	treeBranch = "├── "
+	treeLast   = "└── "
+	treePipe   = "│   "
+	treeSpace  = "    "
+
+	treeSeen  = " (*)"
+	treeCycle = " (cycle)"
+)
+
+type treeRendering struct {
+	*TreeRenderer
+	ew     *errWriter
+	seen   map[*Node]bool
+	inPath map[*Node]bool
+}
+
+// Render writes the tree rooted at n to w
+func (r *TreeRenderer) Render(w io.Writer, n *Node) error {
+	tr := &treeRendering{
+		TreeRenderer: r,
+		ew:           &errWriter{w: w},
+		seen:         make(map[*Node]bool),
+		inPath:       make(map[*Node]bool),
+	}
+
+	tr.ew.printf("%v\n", tr.label(n))
+	tr.render(n, "", 1)
+	return tr.ew.err
+}
+
+func (tr *treeRendering) label(n *Node) string {
+	if tr.Label != nil {
+		return tr.Label(n)
+	}
+
+	return n.graph.label(n)
+}
+
+func (tr *treeRendering) render(n *Node, prefix string, depth int) {
+	tr.seen[n] = true
+	tr.inPath[n] = true
+	defer delete(tr.inPath, n)
+
+	var children []*Node
+	for _, e := range n.Edges {
+		if tr.Direction.follows(n, e) {
+			children = append(children, tr.Direction.next(e))
+		}
+	}
+
+	for i, child := range children {
+		branch, indent := treeBranch, treePipe
+		if i == len(children)-1 {
+			branch, indent = treeLast, treeSpace
+		}
+
+		switch {
+		case tr.inPath[child]:
+			tr.ew.printf("%v%v%v%v\n", prefix, branch, tr.label(child), treeCycle)
+		case tr.seen[child]:
+			tr.ew.printf("%v%v%v%v\n", prefix, branch, tr.label(child), treeSeen)
+		default:
+			tr.ew.printf("%v%v%v\n", prefix, branch, tr.label(child))
+			if tr.MaxDepth == 0 || depth < tr.MaxDepth {
+				tr.render(child, prefix+indent, depth+1)
+			}
+		}
+	}
+}
+
+// RenderDependencies writes the dependency tree of the node to w
+func (n *Node) RenderDependencies(w io.Writer) error {
+	return NewDependencyTreeRenderer().Render(w, n)
+}
+
+// RenderDependents writes the dependent tree of the node to w
+func (n *Node) RenderDependents(w io.Writer) error {
+	return NewDependentTreeRenderer().Render(w, n)
+}
@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGraph_renderTree(t *testing.T) {
+	g := NewGraph()
+
+	a := g.NewNode("a")
+	b := g.NewNode("b")
+	c := g.NewNode("c")
+	d := g.NewNode("d")
+
+	a.DependOn(b)
+	a.DependOn(c)
+	b.DependOn(d)
+	c.DependOn(d)
+	d.DependOn(a)
+
+	var buf bytes.Buffer
+	if err := a.RenderDependencies(&buf); err != nil {
+		t.Fatalf("Unable to render tree: %v", err)
+	}
+
+	expected := "a\n" +
+		"├── b\n" +
+		"│   └── d\n" +
+		"│       └── a (cycle)\n" +
+		"└── c\n" +
+		"    └── d (*)\n"
+	if buf.String() != expected {
+		t.Errorf("Expected\n%v\ngot\n%v", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := d.RenderDependents(&buf); err != nil {
+		t.Fatalf("Unable to render tree: %v", err)
+	}
+
+	expected = "d\n" +
+		"├── b\n" +
+		"│   └── a\n" +
+		"│       └── d (cycle)\n" +
+		"└── c\n" +
+		"    └── a (*)\n"
+	if buf.String() != expected {
+		t.Errorf("Expected\n%v\ngot\n%v", expected, buf.String())
+	}
+}
@@ -0,0 +1,157 @@
+package graph
+
+import "sort"
+
+// ConnectedComponents returns the groups of nodes connected to each other,
+// regardless of the direction of the edges.
+func (g *Graph) ConnectedComponents() (components [][]*Node) {
+	visited := make(map[*Node]bool)
+	for _, root := range g.sortedNodes() {
+		if visited[root] {
+			continue
+		}
+
+		visited[root] = true
+		component := []*Node{root}
+		for i := 0; i < len(component); i++ {
+			for _, e := range component[i].Edges {
+				other := e.Other(component[i])
+				if !visited[other] {
+					visited[other] = true
+					component = append(component, other)
+				}
+			}
+		}
+		components = append(components, component)
+	}
+
+	return
+}
+
+// undirectedComponents returns the 2-edge-connected components of the graph over ordering edges,
+// which are the groups of nodes lying on common cycles, and whether each of them is cyclic,
+// which is the case if it has an edge, including a self-loop.
+func (g *Graph) undirectedComponents() (components [][]*Node, cyclic []bool) {
+	bridges := make(map[*Edge]bool)
+	for _, e := range g.bridges(func(e *Edge) bool { return e.Kind.Orders() }) {
+		bridges[e] = true
+	}
+
+	visited := make(map[*Node]bool)
+	for _, root := range g.sortedNodes() {
+		if visited[root] {
+			continue
+		}
+
+		visited[root] = true
+		component := []*Node{root}
+		hasEdge := false
+		for i := 0; i < len(component); i++ {
+			for _, e := range component[i].Edges {
+				if !e.Kind.Orders() || bridges[e] {
+					continue
+				}
+				hasEdge = true
+
+				other := e.Other(component[i])
+				if !visited[other] {
+					visited[other] = true
+					component = append(component, other)
+				}
+			}
+		}
+
+		components = append(components, component)
+		cyclic = append(cyclic, hasEdge)
+	}
+
+	return
+}
+
+// MinimumSpanningTree returns the edges of a minimum spanning forest,
+// regardless of the direction of the edges, using Kruskal's algorithm.
+// If weight is nil, every edge weighs 1.
+func (g *Graph) MinimumSpanningTree(weight func(*Edge) float64) (tree []*Edge) {
+	nodes := g.sortedNodes()
+	parent := make(map[*Node]*Node, len(nodes))
+	var edges []*Edge
+	for _, n := range nodes {
+		parent[n] = n
+		for _, e := range n.Edges {
+			if e.Source == n && e.Destination != n {
+				edges = append(edges, e)
+			}
+		}
+	}
+
+	if weight != nil {
+		sort.SliceStable(edges, func(i, j int) bool {
+			return weight(edges[i]) < weight(edges[j])
+		})
+	}
+
+	var find func(*Node) *Node
+	find = func(n *Node) *Node {
+		if parent[n] != n {
+			parent[n] = find(parent[n])
+		}
+		return parent[n]
+	}
+
+	for _, e := range edges {
+		a, b := find(e.Source), find(e.Destination)
+		if a == b {
+			continue
+		}
+
+		parent[a] = b
+		tree = append(tree, e)
+	}
+
+	return
+}
+
+// Bridges returns the edges whose removal would disconnect their nodes,
+// regardless of the direction of the edges.
+func (g *Graph) Bridges() []*Edge {
+	return g.bridges(func(*Edge) bool { return true })
+}
+
+// bridges returns the bridges of the graph made of the followed edges
+func (g *Graph) bridges(follow func(*Edge) bool) (bridges []*Edge) {
+	index := make(map[*Node]int)
+	low := make(map[*Node]int)
+
+	var visit func(n *Node, via *Edge)
+	visit = func(n *Node, via *Edge) {
+		index[n] = len(index)
+		low[n] = index[n]
+
+		for _, e := range n.Edges {
+			if e == via || e.Source == e.Destination || !follow(e) {
+				continue
+			}
+
+			other := e.Other(n)
+			if _, visited := index[other]; !visited {
+				visit(other, e)
+				if low[other] < low[n] {
+					low[n] = low[other]
+				}
+				if low[other] > index[n] {
+					bridges = append(bridges, e)
+				}
+			} else if index[other] < low[n] {
+				low[n] = index[other]
+			}
+		}
+	}
+
+	for _, n := range g.sortedNodes() {
+		if _, visited := index[n]; !visited {
+			visit(n, nil)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,128 @@
+package graph
+
+import "testing"
+
+func TestGraph_undirected(t *testing.T) {
+	g := NewGraph()
+	g.Undirected = true
+
+	n1 := g.NewNode(1)
+	n2 := g.NewNode(2)
+	n3 := g.NewNode(3)
+	n4 := g.NewNode(4)
+	n5 := g.NewNode(5)
+
+	e1 := n1.Connect(n2)
+	if n2.Connect(n1) != e1 {
+		t.Errorf("Connections should be symmetric")
+	}
+
+	n2.Connect(n3)
+	n3.Connect(n1)
+	bridge := n3.Connect(n4)
+
+	if n4.DependsOnAdjacent(n3) != bridge {
+		t.Errorf("Adjacency should ignore direction")
+	}
+
+	if len(n3.Neighbors()) != 3 {
+		t.Errorf("Expected 3 neighbors, got %v", n3.Neighbors())
+	}
+
+	components := g.ConnectedComponents()
+	if len(components) != 2 || len(components[0]) != 4 || components[1][0] != n5 {
+		t.Errorf("Unexpected components %v", components)
+	}
+
+	bridges := g.Bridges()
+	if len(bridges) != 1 || bridges[0] != bridge {
+		t.Errorf("Expected one bridge, got %v", bridges)
+	}
+
+	tree := g.MinimumSpanningTree(func(e *Edge) float64 {
+		if e == e1 {
+			return 10
+		}
+		return 1
+	})
+	if len(tree) != 3 {
+		t.Fatalf("Expected 3 edges in the spanning tree, got %v", len(tree))
+	}
+	for _, e := range tree {
+		if e == e1 {
+			t.Errorf("The heaviest edge of the cycle should not be in the spanning tree")
+		}
+	}
+
+	n4.RemoveDependency(n3)
+	if len(n3.Neighbors()) != 2 {
+		t.Errorf("The edge should be removed regardless of direction")
+	}
+
+	if _, err := g.TopologicalSort(nil); err == nil {
+		t.Errorf("Sorting an undirected graph should fail")
+	}
+}
+
+func TestGraph_undirectedCycles(t *testing.T) {
+	for _, reversed := range []bool{false, true} {
+		g := NewGraph()
+		g.Undirected = true
+		a, b, c := g.NewNode("a"), g.NewNode("b"), g.NewNode("c")
+		a.Connect(b)
+		b.Connect(c)
+		if g.HasCyclicDependencies() || len(g.Cycles()) != 0 {
+			t.Errorf("A path should not be cyclic")
+		}
+
+		if reversed {
+			a.Connect(c)
+		} else {
+			c.Connect(a)
+		}
+
+		if !g.HasCyclicDependencies() {
+			t.Errorf("Expected the triangle to be cyclic, reversed: %v", reversed)
+		}
+
+		cycles := g.Cycles()
+		if len(cycles) != 1 || len(cycles[0]) != 3 {
+			t.Errorf("Expected one cycle of 3 nodes, got %v", cycles)
+		}
+
+		d := g.NewNode("d")
+		d.DependOnKind(a, SoftEdge)
+		if components := g.StronglyConnectedComponents(); len(components) != 2 {
+			t.Errorf("Expected 2 components, got %v", components)
+		}
+
+		// A pendant node is on no cycle
+		g.NewNode("e").Connect(a)
+		cycles = g.Cycles()
+		if len(cycles) != 1 || len(cycles[0]) != 3 || findNode(cycles[0], g.Find("e")) >= 0 {
+			t.Errorf("Expected the pendant node to be left out, got %v", cycles)
+		}
+		if components := g.StronglyConnectedComponents(); len(components) != 3 {
+			t.Errorf("Expected 3 components, got %v", components)
+		}
+	}
+}
+
+func TestGraph_undirectedRegionCycles(t *testing.T) {
+	g := NewGraph()
+	g.Undirected = true
+	a := g.NewNode("a").PutIntoRegion(1)
+	b := g.NewNode("b").PutIntoRegion(2)
+	a.Connect(b)
+
+	if report := g.AnalyzeRegions(); len(report.CrossRegionCycles) != 0 {
+		t.Errorf("A single edge should not be a cross region cycle, got %v", report.CrossRegionCycles)
+	}
+
+	c := g.NewNode("c").PutIntoRegion(2)
+	b.Connect(c)
+	c.Connect(a)
+	if report := g.AnalyzeRegions(); len(report.CrossRegionCycles) != 1 || len(report.CrossRegionCycles[0]) != 3 {
+		t.Errorf("Expected one cross region cycle, got %v", report.CrossRegionCycles)
+	}
+}
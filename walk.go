@@ -34,6 +34,30 @@ func NewDepthFirstWalkerWithinSameRegion(cb func(*Node, *Edge)) *Walk {
 	}
 }
 
+// NewDepthFirstWalkerWithinRegionLevel returns a walker following the edges
+// within the same region at the given level of the region hierarchy.
+func NewDepthFirstWalkerWithinRegionLevel(cb func(*Node, *Edge), level int) *Walk {
+	return &Walk{
+		FollowEdge: func(node *Node, edge *Edge) bool {
+			if node != edge.Source {
+				return false
+			}
+
+			return !edge.CrossRegionAt(level)
+		},
+		CallBack: cb,
+	}
+}
+
+// OnlyKinds restricts the walker to edges of the given kinds, in addition to its own criteria
+func (w *Walk) OnlyKinds(kinds ...EdgeKind) *Walk {
+	follow := w.FollowEdge
+	w.FollowEdge = func(node *Node, edge *Edge) bool {
+		return edge.Kind.hasKind(kinds) && follow(node, edge)
+	}
+	return w
+}
+
 func (n *Node) Walk(w *Walk) {
 	w.Walk(n)
 }